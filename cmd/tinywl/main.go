@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -13,6 +14,7 @@ import (
 
 var (
 	command      = flag.String("s", "", "startup command")
+	headless     = flag.Bool("headless", false, "run on a virtual output without a display")
 	programLevel = new(slog.LevelVar) // Info by default
 
 )
@@ -39,7 +41,7 @@ func main() {
 	wlroots.OnLog(wlroots.LogImportanceDebug, nil)
 
 	// start the server
-	server, err := NewServer()
+	server, err := NewServer(*headless)
 	if err != nil {
 		fatal("initializing server", err)
 	}
@@ -52,13 +54,13 @@ func main() {
 		cmd := exec.Command("/bin/sh", "-c", *command)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
-		if err = cmd.Start(); err != nil {
+		if err = server.StartClient(cmd); err != nil {
 			fatal("running startup command", err)
 		}
 	}
 
 	// start the wayland event loop
-	if err = server.Run(); err != nil {
+	if err = server.Run(context.Background()); err != nil {
 		fatal("running server", err)
 	}
 }
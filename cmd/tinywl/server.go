@@ -2,9 +2,13 @@ package main
 
 import (
 	"container/list"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
+	"syscall"
 	"time"
 
 	"github.com/swaywm/go-wlroots/wlroots"
@@ -616,30 +620,53 @@ func (s *Server) beginInteractive(topLevel *wlroots.XDGTopLevel, mode CursorMode
 	}
 }
 
-func NewServer() (s *Server, err error) {
+func NewServer(headless bool) (s *Server, err error) {
 	s = new(Server)
 
 	/* The Wayland display is managed by libwayland. It handles accepting
 	 * clients from the Unix socket, manging Wayland globals, and so on. */
 	s.display = wlroots.NewDisplay()
 
-	/* The backend is a wlroots feature which abstracts the underlying input and
-	 * output hardware. The autocreate option will choose the most suitable
-	 * backend based on the current environment, such as opening an X11 window
-	 * if an X11 server is running. */
-	s.backend, err = s.display.BackendAutocreate()
-	if err != nil {
-		return nil, err
+	if headless {
+		/* The headless backend has no input devices and only the virtual
+		 * outputs we add to it, so the compositor can run without any display
+		 * at all, e.g. on CI machines. Without a GPU, the pixman renderer
+		 * does all rendering in software. */
+		var backend wlroots.HeadlessBackend
+		backend, err = wlroots.NewHeadlessBackend(s.display.EventLoop())
+		if err != nil {
+			return nil, err
+		}
+		if _, err = backend.AddOutput(1920, 1080); err != nil {
+			return nil, err
+		}
+		s.backend = backend.Backend
+
+		s.renderer, err = wlroots.NewPixmanRenderer()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		/* The backend is a wlroots feature which abstracts the underlying
+		 * input and output hardware. The autocreate option will choose the
+		 * most suitable backend based on the current environment, such as
+		 * opening an X11 window if an X11 server is running. */
+		s.backend, err = s.display.BackendAutocreate()
+		if err != nil {
+			return nil, err
+		}
+
+		/* Autocreates a renderer, either Pixman, GLES2 or Vulkan for us. The
+		 * user can also specify a renderer using the WLR_RENDERER env var. */
+		s.renderer, err = s.backend.RendererAutoCreate()
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("created renderer", "pixman", s.renderer.IsPixman(), "gles2", s.renderer.IsGLES2(), "vulkan", s.renderer.IsVulkan())
 	}
 
-	/* Autocreates a renderer, either Pixman, GLES2 or Vulkan for us. The user
-	 * can also specify a renderer using the WLR_RENDERER env var.
-	 * The renderer is responsible for defining the various pixel formats it
+	/* The renderer is responsible for defining the various pixel formats it
 	 * supports for shared memory, this configures that for clients. */
-	s.renderer, err = s.backend.RendererAutoCreate()
-	if err != nil {
-		return nil, err
-	}
 	s.renderer.InitDisplay(s.display)
 
 	/* Autocreates an allocator for us.
@@ -662,6 +689,12 @@ func NewServer() (s *Server, err error) {
 	s.display.SubCompositorCreate()
 	s.display.DataDeviceManagerCreate()
 
+	/* Lets screenshot tools such as grim and recorders capture the outputs.
+	 * A compositor that cares about privacy would set a capture policy on
+	 * these to only allow trusted clients. */
+	s.display.NewScreencopyManagerV1()
+	s.display.NewExportDmabufManagerV1()
+
 	/* Creates an output layout, which a wlroots utility for working with an
 	 * arrangement of screens in a physical layout. */
 	s.outputLayout = wlroots.NewOutputLayout(s.display)
@@ -730,9 +763,23 @@ func NewServer() (s *Server, err error) {
 	s.seat = s.display.SeatCreate("seat0")
 	s.seat.OnSetCursorRequest(s.handleSetCursorRequest)
 
+	/* Terminate the display, and thereby shut down cleanly, when the process
+	 * is asked to stop. */
+	evl := s.display.EventLoop()
+	for _, sig := range []os.Signal{syscall.SIGINT, syscall.SIGTERM} {
+		if _, err = evl.AddSignal(sig, s.handleSignal); err != nil {
+			return nil, err
+		}
+	}
+
 	return
 }
 
+func (s *Server) handleSignal(sig os.Signal) {
+	slog.Info("Received signal, shutting down", "signal", sig)
+	s.display.Terminate()
+}
+
 func (s *Server) Start() (err error) {
 
 	var socket string
@@ -760,20 +807,44 @@ func (s *Server) Start() (err error) {
 	return
 }
 
-func (s *Server) Run() error {
+func (s *Server) StartClient(cmd *exec.Cmd) error {
+	/* Connect the command through a private socket instead of the public one,
+	 * libwayland picks it up from WAYLAND_SOCKET. This also means the client
+	 * is known to us before it even runs. */
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err = s.display.CreateClient(fds[0]); err != nil {
+		syscall.Close(fds[0])
+		syscall.Close(fds[1])
+		return err
+	}
+
+	/* The child end becomes fd 3 in the new process, the first of ExtraFiles. */
+	sock := os.NewFile(uintptr(fds[1]), "wayland-socket")
+	defer sock.Close()
+	cmd.ExtraFiles = append(cmd.ExtraFiles, sock)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("WAYLAND_SOCKET=%d", 2+len(cmd.ExtraFiles)))
+	return cmd.Start()
+}
+
+func (s *Server) Run(ctx context.Context) error {
 
 	/* Run the Wayland event loop. This does not return until you exit the
-	 * compositor. Starting the backend rigged up all of the necessary event
-	 * loop configuration to listen to libinput events, DRM events, generate
-	 * frame events at the refresh rate, and so on. */
-	s.display.Run()
-
-	/* Once s.display.Run() returns, we destroy all clients then shut down the
-	 * server. */
-	s.display.DestroyClients()
-	s.scene.Tree().Node().Destroy()
-	s.cursorMgr.Destroy()
-	s.outputLayout.Destroy()
-	s.display.Destroy()
-	return nil
+	 * compositor or ctx is cancelled. Starting the backend rigged up all of
+	 * the necessary event loop configuration to listen to libinput events,
+	 * DRM events, generate frame events at the refresh rate, and so on. */
+	err := s.display.RunContext(ctx)
+	if errors.Is(err, wlroots.ErrDisplayTerminated) {
+		err = nil
+	}
+
+	/* Once the event loop has stopped, we destroy all clients then shut down
+	 * the server. */
+	s.display.Shutdown(s.scene, s.cursorMgr, s.outputLayout)
+	return err
 }
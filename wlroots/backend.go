@@ -22,16 +22,19 @@ type Backend struct {
 }
 
 func (b Backend) Destroy() {
+	debugCheck(unsafe.Pointer(b.p))
 	C.wlr_backend_destroy(b.p)
 }
 
-func (b Backend) OnDestroy(cb func(Backend)) {
-	man.add(unsafe.Pointer(b.p), &b.p.events.destroy, func(unsafe.Pointer) {
+func (b Backend) OnDestroy(cb func(Backend)) Listener {
+	debugCheck(unsafe.Pointer(b.p))
+	return man.add(b.p, &b.p.events.destroy, func(unsafe.Pointer) {
 		cb(b)
 	})
 }
 
 func (b Backend) Start() error {
+	debugCheck(unsafe.Pointer(b.p))
 	if !C.wlr_backend_start(b.p) {
 		return errors.New("can't start backend")
 	}
@@ -39,18 +42,33 @@ func (b Backend) Start() error {
 	return nil
 }
 
-func (b Backend) OnNewOutput(cb func(Output)) {
-	man.add(unsafe.Pointer(b.p), &b.p.events.new_output, func(data unsafe.Pointer) {
+/**
+ * Returns the DRM node file descriptor used by the backend's underlying
+ * platform. Can be used by consumers for additional rendering operations.
+ * The consumer must not close the file descriptor since the backend continues
+ * to have ownership of it.
+ *
+ * Returns -1 if the backend has no DRM device.
+ */
+func (b Backend) DRMFD() int {
+	debugCheck(unsafe.Pointer(b.p))
+	return int(C.wlr_backend_get_drm_fd(b.p))
+}
+
+func (b Backend) OnNewOutput(cb func(Output)) Listener {
+	debugCheck(unsafe.Pointer(b.p))
+	return man.add(b.p, &b.p.events.new_output, func(data unsafe.Pointer) {
 		output := wrapOutput(data)
-		man.track(unsafe.Pointer(output.p), &output.p.events.destroy)
+		man.track(output.p, &output.p.events.destroy)
 		cb(output)
 	})
 }
 
-func (b Backend) OnNewInput(cb func(InputDevice)) {
-	man.add(unsafe.Pointer(b.p), &b.p.events.new_input, func(data unsafe.Pointer) {
+func (b Backend) OnNewInput(cb func(InputDevice)) Listener {
+	debugCheck(unsafe.Pointer(b.p))
+	return man.add(b.p, &b.p.events.new_input, func(data unsafe.Pointer) {
 		dev := wrapInputDevice(data)
-		man.add(unsafe.Pointer(dev.p), &dev.p.events.destroy, func(data unsafe.Pointer) {
+		man.addLast(dev.p, &dev.p.events.destroy, func(data unsafe.Pointer) {
 			// delete the wlr_input_device
 			man.delete(unsafe.Pointer(dev.p))
 		})
@@ -59,28 +77,32 @@ func (b Backend) OnNewInput(cb func(InputDevice)) {
 }
 
 func (b Backend) AllocatorAutocreate(r Renderer) (Allocator, error) {
+	debugCheck(unsafe.Pointer(b.p))
 	p := C.wlr_allocator_autocreate(b.p, r.p)
 	if p == nil {
 		return Allocator{}, errors.New("failed to wlr_allocator")
 	}
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return Allocator{p: p}, nil
 }
 
 func (b Backend) NewAllocator(r Renderer) (Allocator, error) {
+	debugCheck(unsafe.Pointer(b.p))
 	return b.AllocatorAutocreate(r)
 }
 
 func (b Backend) RendererAutoCreate() (Renderer, error) {
+	debugCheck(unsafe.Pointer(b.p))
 	p := C.wlr_renderer_autocreate(b.p)
 	if p == nil {
 		return Renderer{}, errors.New("failed to create wlr_renderer")
 	}
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return Renderer{p: p}, nil
 }
 
 func (b Backend) NewRenderer() (Renderer, error) {
+	debugCheck(unsafe.Pointer(b.p))
 	return b.RendererAutoCreate()
 }
 
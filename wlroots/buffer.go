@@ -5,9 +5,76 @@ package wlroots
  * future consistency of this API.
  */
 
-// #cgo pkg-config: wlroots-0.18 wayland-server
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"sync"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server libdrm
 // #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdlib.h>
+// #include <drm_fourcc.h>
+// #include <pixman.h>
+// #include <wlr/interfaces/wlr_buffer.h>
 // #include <wlr/types/wlr_buffer.h>
+// #include <wlr/types/wlr_scene.h>
+//
+// struct _go_buffer {
+//		struct wlr_buffer base;
+//		void *data;
+//		size_t stride;
+// };
+//
+// static void _go_buffer_destroy(struct wlr_buffer *wlr_buffer) {
+//		struct _go_buffer *buffer = wl_container_of(wlr_buffer, buffer, base);
+//		free(buffer->data);
+//		free(buffer);
+// }
+//
+// static bool _go_buffer_begin_data_ptr_access(struct wlr_buffer *wlr_buffer,
+//		uint32_t flags, void **data, uint32_t *format, size_t *stride) {
+//		struct _go_buffer *buffer = wl_container_of(wlr_buffer, buffer, base);
+//		*data = buffer->data;
+//		*format = DRM_FORMAT_ABGR8888;
+//		*stride = buffer->stride;
+//		return true;
+// }
+//
+// static void _go_buffer_end_data_ptr_access(struct wlr_buffer *wlr_buffer) {
+//		// nothing to do, the pixels are always mapped
+// }
+//
+// static const struct wlr_buffer_impl _go_buffer_impl = {
+//		.destroy = _go_buffer_destroy,
+//		.begin_data_ptr_access = _go_buffer_begin_data_ptr_access,
+//		.end_data_ptr_access = _go_buffer_end_data_ptr_access,
+// };
+//
+// static inline struct _go_buffer *_go_buffer_create(int width, int height) {
+//		struct _go_buffer *buffer = calloc(1, sizeof(*buffer));
+//		if (buffer == NULL) {
+//			return NULL;
+//		}
+//		buffer->stride = (size_t)width * 4;
+//		buffer->data = calloc(height, buffer->stride);
+//		if (buffer->data == NULL) {
+//			free(buffer);
+//			return NULL;
+//		}
+//		wlr_buffer_init(&buffer->base, &_go_buffer_impl, width, height);
+//		return buffer;
+// }
+//
+// static inline void _wlr_scene_buffer_damage(struct wlr_scene_buffer *scene_buffer,
+//		struct wlr_buffer *buffer, int x, int y, int width, int height) {
+//		pixman_region32_t damage;
+//		pixman_region32_init_rect(&damage, x, y, width, height);
+//		wlr_scene_buffer_set_buffer_with_damage(scene_buffer, buffer, &damage);
+//		pixman_region32_fini(&damage);
+// }
 import "C"
 
 /**
@@ -47,3 +114,266 @@ func (b Buffer) Lock() Buffer {
 func (b Buffer) Unlock() {
 	C.wlr_buffer_unlock(b.p)
 }
+
+func (b Buffer) Nil() bool {
+	return b.p == nil
+}
+
+func (b Buffer) Width() int {
+	return int(b.p.width)
+}
+
+func (b Buffer) Height() int {
+	return int(b.p.height)
+}
+
+type BufferAccessFlags uint32
+
+const (
+	// The buffer contents can be read back.
+	BufferAccessRead BufferAccessFlags = C.WLR_BUFFER_DATA_PTR_ACCESS_READ
+	// The buffer contents can be written to.
+	BufferAccessWrite BufferAccessFlags = C.WLR_BUFFER_DATA_PTR_ACCESS_WRITE
+)
+
+/**
+ * Get a pointer to a region of memory referring to the buffer's underlying
+ * storage. The format and stride can be used to interpret the memory region
+ * contents.
+ *
+ * The data slice passed to fn is only valid for the duration of the call and
+ * must not be retained. Access fails if the buffer doesn't support data
+ * pointer access, e.g. for most DMA-BUFs.
+ */
+func (b Buffer) Access(flags BufferAccessFlags, fn func(data []byte, format uint32, stride int) error) error {
+	debugCheck(unsafe.Pointer(b.p))
+	var (
+		data   unsafe.Pointer
+		format C.uint32_t
+		stride C.size_t
+	)
+	if !C.wlr_buffer_begin_data_ptr_access(b.p, C.uint32_t(flags), &data, &format, &stride) {
+		return errors.New("buffer doesn't support data pointer access")
+	}
+	defer C.wlr_buffer_end_data_ptr_access(b.p)
+
+	buf := unsafe.Slice((*byte)(data), int(stride)*int(b.p.height))
+	return fn(buf, uint32(format), int(stride))
+}
+
+/** Shared-memory attributes for a buffer. */
+type ShmAttributes struct {
+	// Owned by the buffer, must not be closed.
+	FD     int
+	Format uint32
+	Width  int
+	Height int
+	Stride int
+	Offset int64
+}
+
+/**
+ * Read shared memory attributes of the buffer. If this buffer isn't shared
+ * memory, returns false.
+ *
+ * The returned file descriptor belongs to the buffer and stays valid as long
+ * as it is locked.
+ */
+func (b Buffer) ShmAttributes() (ShmAttributes, bool) {
+	var attr C.struct_wlr_shm_attributes
+	if !C.wlr_buffer_get_shm(b.p, &attr) {
+		return ShmAttributes{}, false
+	}
+	return ShmAttributes{
+		FD:     int(attr.fd),
+		Format: uint32(attr.format),
+		Width:  int(attr.width),
+		Height: int(attr.height),
+		Stride: int(attr.stride),
+		Offset: int64(attr.offset),
+	}, true
+}
+
+/** A single plane of a DMA-BUF. */
+type DmabufPlane struct {
+	// Owned by the buffer, must not be closed.
+	FD     int
+	Offset uint32
+	Stride uint32
+}
+
+/** DMA-BUF attributes for a buffer. */
+type DmabufAttributes struct {
+	Width    int
+	Height   int
+	Format   uint32
+	Modifier uint64
+	Planes   []DmabufPlane
+}
+
+/**
+ * Reads the DMA-BUF attributes of the buffer. If this buffer isn't a DMA-BUF,
+ * returns false.
+ *
+ * The returned DMA-BUF attributes are valid for the lifetime of the
+ * buffer. The caller must not close the file descriptors.
+ */
+func (b Buffer) DmabufAttributes() (DmabufAttributes, bool) {
+	var attr C.struct_wlr_dmabuf_attributes
+	if !C.wlr_buffer_get_dmabuf(b.p, &attr) {
+		return DmabufAttributes{}, false
+	}
+	d := DmabufAttributes{
+		Width:    int(attr.width),
+		Height:   int(attr.height),
+		Format:   uint32(attr.format),
+		Modifier: uint64(attr.modifier),
+	}
+	for i := 0; i < int(attr.n_planes); i++ {
+		d.Planes = append(d.Planes, DmabufPlane{
+			FD:     int(attr.fd[i]),
+			Offset: uint32(attr.offset[i]),
+			Stride: uint32(attr.stride[i]),
+		})
+	}
+	return d, true
+}
+
+// BufferImpl draws the contents of a buffer created with NewBuffer.
+type BufferImpl interface {
+	// Draw paints the pixels inside r. dst spans the whole buffer, and
+	// anything drawn outside of r may not show up on screen.
+	Draw(dst *image.RGBA, r image.Rectangle)
+}
+
+// ImageBuffer is a buffer whose pixels are drawn by Go code. Its memory is
+// owned by wlroots, so it can be handed to the renderer and the scene-graph
+// like any client buffer.
+type ImageBuffer struct {
+	Buffer
+}
+
+type imageBufferState struct {
+	impl BufferImpl
+	img  *image.RGBA
+
+	// scene-graph nodes displaying the buffer, redrawn by Update
+	nodes map[*C.struct_wlr_scene_buffer]Listener
+}
+
+var (
+	imageBuffers      = map[*C.struct_wlr_buffer]*imageBufferState{}
+	imageBuffersMutex sync.Mutex
+)
+
+// NewBuffer creates a width x height buffer and lets impl draw all of it.
+//
+// Like any producer, the caller should Drop the buffer once it no longer
+// needs it; the memory is freed when the last consumer unlocks it.
+func NewBuffer(width int, height int, impl BufferImpl) (ImageBuffer, error) {
+	if width <= 0 || height <= 0 {
+		return ImageBuffer{}, errors.New("invalid buffer size")
+	}
+
+	buf := C._go_buffer_create(C.int(width), C.int(height))
+	if buf == nil {
+		return ImageBuffer{}, errors.New("failed to allocate buffer")
+	}
+	p := &buf.base
+
+	// the pixels are in DRM_FORMAT_ABGR8888, which has the same byte order
+	// as image.RGBA
+	s := &imageBufferState{
+		impl: impl,
+		img: &image.RGBA{
+			Pix:    unsafe.Slice((*uint8)(buf.data), int(buf.stride)*height),
+			Stride: int(buf.stride),
+			Rect:   image.Rect(0, 0, width, height),
+		},
+		nodes: map[*C.struct_wlr_scene_buffer]Listener{},
+	}
+
+	imageBuffersMutex.Lock()
+	imageBuffers[p] = s
+	imageBuffersMutex.Unlock()
+
+	man.track(p, &p.events.destroy)
+	man.addLast(p, &p.events.destroy, func(unsafe.Pointer) {
+		imageBuffersMutex.Lock()
+		delete(imageBuffers, p)
+		imageBuffersMutex.Unlock()
+		for _, l := range s.nodes {
+			l.Remove()
+		}
+		s.img.Pix = nil
+	})
+
+	impl.Draw(s.img, s.img.Rect)
+	return ImageBuffer{Buffer{p: p}}, nil
+}
+
+type imageBufferImpl struct {
+	src image.Image
+}
+
+func (i imageBufferImpl) Draw(dst *image.RGBA, r image.Rectangle) {
+	draw.Draw(dst, r, i.src, i.src.Bounds().Min.Add(r.Min), draw.Src)
+}
+
+// NewBufferFromImage creates a buffer the size of img and copies img into
+// it. Later changes to img show up after a call to Update.
+func NewBufferFromImage(img image.Image) (ImageBuffer, error) {
+	return NewBuffer(img.Bounds().Dx(), img.Bounds().Dy(), imageBufferImpl{src: img})
+}
+
+func lookupImageBuffer(p *C.struct_wlr_buffer) *imageBufferState {
+	imageBuffersMutex.Lock()
+	defer imageBuffersMutex.Unlock()
+	return imageBuffers[p]
+}
+
+// Update redraws region, given in buffer coordinates, and damages it on every
+// scene-graph node displaying the buffer.
+func (b ImageBuffer) Update(region image.Rectangle) {
+	debugCheck(unsafe.Pointer(b.p))
+	s := lookupImageBuffer(b.p)
+	if s == nil {
+		return
+	}
+	region = region.Intersect(s.img.Rect)
+	if region.Empty() {
+		return
+	}
+
+	s.impl.Draw(s.img, region)
+
+	// re-attaching the buffer briefly unlocks it, which would destroy a
+	// dropped buffer held by a single node
+	C.wlr_buffer_lock(b.p)
+	defer C.wlr_buffer_unlock(b.p)
+	for sb := range s.nodes {
+		C._wlr_scene_buffer_damage(sb, b.p, C.int(region.Min.X), C.int(region.Min.Y),
+			C.int(region.Dx()), C.int(region.Dy()))
+	}
+}
+
+// setSceneBuffer keeps track of the scene-graph nodes showing image buffers,
+// so that Update can damage them. Must be called whenever the buffer of a
+// scene buffer node changes.
+func setSceneBuffer(sb *C.struct_wlr_scene_buffer, old *C.struct_wlr_buffer, new *C.struct_wlr_buffer) {
+	if sb == nil || old == new {
+		return
+	}
+	if s := lookupImageBuffer(old); s != nil {
+		if l, found := s.nodes[sb]; found {
+			l.Remove()
+			delete(s.nodes, sb)
+		}
+	}
+	if s := lookupImageBuffer(new); s != nil {
+		s.nodes[sb] = man.add(sb, &sb.node.events.destroy, func(unsafe.Pointer) {
+			s.nodes[sb].Remove()
+			delete(s.nodes, sb)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import "unsafe"
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <sys/types.h>
+// #include <wayland-server-core.h>
+import "C"
+
+/**
+ * A Wayland client, i.e. a single connection to the display.
+ */
+type Client struct {
+	p *C.struct_wl_client
+}
+
+// ClientCredentials are the credentials of the process on the other end of
+// the client's socket, as reported by SO_PEERCRED when it connected.
+type ClientCredentials struct {
+	PID int
+	UID int
+	GID int
+}
+
+func (c Client) Nil() bool {
+	return c.p == nil
+}
+
+/**
+ * Return Unix credentials for the client.
+ *
+ * This function returns the process ID, the user ID and the group ID for the
+ * given client. The credentials come from getsockopt() with SO_PEERCRED, on
+ * the client socket fd.
+ */
+func (c Client) Credentials() ClientCredentials {
+	debugCheck(unsafe.Pointer(c.p))
+	var pid C.pid_t
+	var uid C.uid_t
+	var gid C.gid_t
+	C.wl_client_get_credentials(c.p, &pid, &uid, &gid)
+	return ClientCredentials{PID: int(pid), UID: int(uid), GID: int(gid)}
+}
+
+/**
+ * Disconnect the client and free all of its resources.
+ */
+func (c Client) Destroy() {
+	debugCheck(unsafe.Pointer(c.p))
+	C.wl_client_destroy(c.p)
+}
+
+/**
+ * Flush pending events to the client.
+ */
+func (c Client) Flush() {
+	debugCheck(unsafe.Pointer(c.p))
+	C.wl_client_flush(c.p)
+}
+
+func (c Client) Display() Display {
+	debugCheck(unsafe.Pointer(c.p))
+	return Display{p: C.wl_client_get_display(c.p)}
+}
+
+func (c Client) OnDestroy(cb func(Client)) Listener {
+	debugCheck(unsafe.Pointer(c.p))
+	var l Listener
+	l = man.add(c.p, nil, func(unsafe.Pointer) {
+		// libwayland has removed the listener from the client already, free it
+		// as nothing else will
+		defer l.Remove()
+		cb(c)
+	})
+	C.wl_client_add_destroy_listener(c.p, l.l.p)
+	return l
+}
+
+func (d Display) OnClientCreated(cb func(Client)) Listener {
+	debugCheck(unsafe.Pointer(d.p))
+	l := man.add(d.p, nil, func(data unsafe.Pointer) {
+		cb(Client{p: (*C.struct_wl_client)(data)})
+	})
+	C.wl_display_add_client_created_listener(d.p, l.l.p)
+	return l
+}
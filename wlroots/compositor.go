@@ -27,8 +27,8 @@ type Compositor struct {
 	p *C.struct_wlr_compositor
 }
 
-func (c Compositor) OnDestroy(cb func(Compositor)) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.destroy, func(unsafe.Pointer) {
+func (c Compositor) OnDestroy(cb func(Compositor)) Listener {
+	return man.add(c.p, &c.p.events.destroy, func(unsafe.Pointer) {
 		cb(c)
 	})
 }
@@ -37,8 +37,8 @@ type SubCompositor struct {
 	p *C.struct_wlr_subcompositor
 }
 
-func (c SubCompositor) OnDestroy(cb func(SubCompositor)) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.destroy, func(unsafe.Pointer) {
+func (c SubCompositor) OnDestroy(cb func(SubCompositor)) Listener {
+	return man.add(c.p, &c.p.events.destroy, func(unsafe.Pointer) {
 		cb(c)
 	})
 }
@@ -61,6 +61,7 @@ type Surface struct {
  * This function must only be used by surface role implementations.
  */
 func (s Surface) Map() {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_surface_map(s.p)
 }
 
@@ -70,6 +71,7 @@ func (s Surface) Map() {
  * This function must only be used by surface role implementations.
  */
 func (s Surface) Unmap() {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_surface_unmap(s.p)
 }
 
@@ -80,6 +82,7 @@ func (s Surface) Unmap() {
  * committed a null buffer, or something went wrong with uploading the buffer.
  */
 func (s Surface) HasBuffer() bool {
+	debugCheck(unsafe.Pointer(s.p))
 	return bool(C.wlr_surface_has_buffer(s.p))
 }
 
@@ -89,6 +92,7 @@ func (s Surface) HasBuffer() bool {
  * uploading the buffer.
  */
 func (s Surface) Texture() Texture {
+	debugCheck(unsafe.Pointer(s.p))
 	p := C.wlr_surface_get_texture(s.p)
 	return Texture{p}
 }
@@ -98,6 +102,7 @@ func (s Surface) Texture() Texture {
  * a surface in the tree has been destroyed.
  */
 func (s Surface) RootSurface() Surface {
+	debugCheck(unsafe.Pointer(s.p))
 	p := C.wlr_surface_get_root_surface(s.p)
 	return Surface{p}
 }
@@ -108,6 +113,7 @@ func (s Surface) RootSurface() Surface {
  * This is a no-op if the surface has already entered the output.
  */
 func (s Surface) SendEnter(o Output) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_surface_send_enter(s.p, o.p)
 }
 
@@ -117,6 +123,7 @@ func (s Surface) SendEnter(o Output) {
  * This is a no-op if the surface has already left the output.
  */
 func (s Surface) SendLeave(o Output) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_surface_send_leave(s.p, o.p)
 }
 
@@ -124,13 +131,36 @@ func (s Surface) Nil() bool {
 	return s.p == nil
 }
 
-func (s Surface) OnDestroy(cb func(Surface)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.destroy, func(unsafe.Pointer) {
+/**
+ * The client that created this surface.
+ */
+func (s Surface) Client() Client {
+	debugCheck(unsafe.Pointer(s.p))
+	return Client{p: C.wl_resource_get_client(s.p.resource)}
+}
+
+func (s Surface) OnDestroy(cb func(Surface)) Listener {
+	debugCheck(unsafe.Pointer(s.p))
+	return man.add(s.p, &s.p.events.destroy, func(unsafe.Pointer) {
 		cb(s)
 	})
 }
 
+// SetUserData attaches an arbitrary Go value to the surface. The value is
+// released once the surface is destroyed. Passing nil removes it.
+func (s Surface) SetUserData(v any) {
+	debugCheck(unsafe.Pointer(s.p))
+	man.setUserData(s.p, &s.p.events.destroy, v)
+}
+
+// UserData returns the value set with SetUserData, or nil.
+func (s Surface) UserData() any {
+	debugCheck(unsafe.Pointer(s.p))
+	return man.getUserData(unsafe.Pointer(s.p))
+}
+
 func (s Surface) Type() SurfaceType {
+	debugCheck(unsafe.Pointer(s.p))
 	if C.wlr_xdg_surface_try_from_wlr_surface(s.p) != nil {
 		return SurfaceTypeXDG
 	} else if C.wlr_xwayland_surface_try_from_wlr_surface(s.p) != nil {
@@ -140,16 +170,19 @@ func (s Surface) Type() SurfaceType {
 }
 
 func (s Surface) SurfaceAt(sx float64, sy float64) (surface Surface, subX float64, subY float64) {
+	debugCheck(unsafe.Pointer(s.p))
 	var csubX, csubY C.double
 	p := C.wlr_surface_surface_at(s.p, C.double(sx), C.double(sy), &csubX, &csubY)
 	return Surface{p: p}, float64(csubX), float64(csubY)
 }
 
 func (s Surface) CurrentState() SurfaceState {
+	debugCheck(unsafe.Pointer(s.p))
 	return SurfaceState{p: s.p.current}
 }
 
 func (s Surface) Walk(visit func()) {
+	debugCheck(unsafe.Pointer(s.p))
 	panic("not implemented")
 }
 
@@ -160,6 +193,7 @@ func (s Surface) Walk(visit func()) {
  * draw its next frame.
  */
 func (s Surface) SendFrameDone(when time.Time) {
+	debugCheck(unsafe.Pointer(s.p))
 	// we ignore the returned error; the only possible error is
 	// ERANGE, when timespec on a platform has int32 precision, but
 	// our time requires 64 bits. This should not occur.
@@ -168,11 +202,13 @@ func (s Surface) SendFrameDone(when time.Time) {
 }
 
 func (s Surface) XDGSurface() XDGSurface {
+	debugCheck(unsafe.Pointer(s.p))
 	p := C.wlr_xdg_surface_try_from_wlr_surface(s.p)
 	return XDGSurface{p: p}
 }
 
 func (s Surface) XDGTopLevel() (XDGTopLevel, error) {
+	debugCheck(unsafe.Pointer(s.p))
 	p := C.wlr_xdg_toplevel_try_from_wlr_surface(s.p)
 	if p == nil {
 		return XDGTopLevel{}, errors.New("no xdg top level")
@@ -187,6 +223,7 @@ func (s Surface) XDGTopLevel() (XDGTopLevel, error) {
  * associated, NULL is returned.
  */
 func (s Surface) XWaylandSurface() XWaylandSurface {
+	debugCheck(unsafe.Pointer(s.p))
 	p := C.wlr_xwayland_surface_try_from_wlr_surface(s.p)
 	return XWaylandSurface{p: p}
 }
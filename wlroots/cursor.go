@@ -31,19 +31,23 @@ type Cursor struct {
 
 func NewCursor() Cursor {
 	p := C.wlr_cursor_create()
+	debugAlive(unsafe.Pointer(p))
 	return Cursor{p: p}
 }
 
 func (c Cursor) Destroy() {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_destroy(c.p)
 	man.delete(unsafe.Pointer(c.p))
 }
 
 func (c Cursor) X() float64 {
+	debugCheck(unsafe.Pointer(c.p))
 	return float64(c.p.x)
 }
 
 func (c Cursor) Y() float64 {
+	debugCheck(unsafe.Pointer(c.p))
 	return float64(c.p.y)
 }
 
@@ -53,6 +57,7 @@ func (c Cursor) Y() float64 {
  * direction and do not support absolute input events.
  */
 func (c Cursor) AttachOutputLayout(layout OutputLayout) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_attach_output_layout(c.p, layout.p)
 }
 
@@ -62,6 +67,7 @@ func (c Cursor) AttachOutputLayout(layout OutputLayout) {
  * without an associated output layout.
  */
 func (c Cursor) MapToOutput(output Output) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_map_to_output(c.p, output.p)
 }
 
@@ -71,6 +77,7 @@ func (c Cursor) MapToOutput(output Output) {
  * outputs in the attached output layout.
  */
 func (c Cursor) MapInputToOutput(input InputDevice, output Output) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_map_input_to_output(c.p, input.p, output.p)
 }
 
@@ -79,6 +86,7 @@ func (c Cursor) MapInputToOutput(input InputDevice, output Output) {
  * struct wlr_output_layout.
  */
 func (c Cursor) MapToRegion(box GeoBox) {
+	debugCheck(unsafe.Pointer(c.p))
 	cbox := box.toC()
 	C.wlr_cursor_map_to_region(c.p, &cbox)
 }
@@ -88,6 +96,7 @@ func (c Cursor) MapToRegion(box GeoBox) {
  * struct wlr_output_layout.
  */
 func (c Cursor) MapInputToRegion(dev InputDevice, box GeoBox) {
+	debugCheck(unsafe.Pointer(c.p))
 	cbox := box.toC()
 	C.wlr_cursor_map_input_to_region(c.p, dev.p, &cbox)
 }
@@ -100,10 +109,12 @@ func (c Cursor) MapInputToRegion(dev InputDevice, box GeoBox) {
  * - WLR_INPUT_DEVICE_TABLET_TOOL
  */
 func (c Cursor) AttachInputDevice(dev InputDevice) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_attach_input_device(c.p, dev.p)
 }
 
 func (c Cursor) DetachInputDevice(dev InputDevice) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_detach_input_device(c.p, dev.p)
 }
 
@@ -113,6 +124,7 @@ func (c Cursor) DetachInputDevice(dev InputDevice) {
  * The image will be loaded from the struct wlr_xcursor_manager.
  */
 func (c Cursor) SetXCursor(cm XCursorManager, name string) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_set_xcursor(c.p, cm.p, C.CString(name))
 }
 
@@ -124,6 +136,7 @@ func (c Cursor) SetXCursor(cm XCursorManager, name string) {
  * device mapping constraints will be ignored.
  */
 func (c Cursor) Move(dev InputDevice, dx float64, dy float64) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_move(c.p, dev.p, C.double(dx), C.double(dy))
 }
 
@@ -136,6 +149,7 @@ func (c Cursor) Move(dev InputDevice, dx float64, dy float64) {
  * device mapping constraints will be ignored.
  */
 func (c Cursor) WarpAbsolute(dev InputDevice, x float64, y float64) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_warp_absolute(c.p, dev.p, C.double(x), C.double(y))
 }
 
@@ -145,6 +159,7 @@ func (c Cursor) WarpAbsolute(dev InputDevice, x float64, y float64) {
  * commit hides the cursor.
  */
 func (c Cursor) SetSurface(surface Surface, hotspotX int32, hotspotY int32) {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_set_surface(c.p, surface.p, C.int32_t(hotspotX), C.int32_t(hotspotY))
 }
 
@@ -152,43 +167,49 @@ func (c Cursor) SetSurface(surface Surface, hotspotX int32, hotspotY int32) {
  * Hide the cursor image.
  */
 func (c Cursor) UnsetImage() {
+	debugCheck(unsafe.Pointer(c.p))
 	C.wlr_cursor_unset_image(c.p)
 }
 
-func (c Cursor) OnMotion(cb func(dev InputDevice, time uint32, dx float64, dy float64)) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.motion, func(data unsafe.Pointer) {
+func (c Cursor) OnMotion(cb func(dev InputDevice, time uint32, dx float64, dy float64)) Listener {
+	debugCheck(unsafe.Pointer(c.p))
+	return man.add(c.p, &c.p.events.motion, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_pointer_motion_event)(data)
 		dev := InputDevice{p: &event.pointer.base}
 		cb(dev, uint32(event.time_msec), float64(event.delta_x), float64(event.delta_y))
 	})
 }
 
-func (c Cursor) OnMotionAbsolute(cb func(dev InputDevice, time uint32, x float64, y float64)) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.motion_absolute, func(data unsafe.Pointer) {
+func (c Cursor) OnMotionAbsolute(cb func(dev InputDevice, time uint32, x float64, y float64)) Listener {
+	debugCheck(unsafe.Pointer(c.p))
+	return man.add(c.p, &c.p.events.motion_absolute, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_pointer_motion_absolute_event)(data)
 		dev := InputDevice{p: &event.pointer.base}
 		cb(dev, uint32(event.time_msec), float64(event.x), float64(event.y))
 	})
 }
 
-func (c Cursor) OnButton(cb func(dev InputDevice, time uint32, button uint32, state ButtonState)) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.button, func(data unsafe.Pointer) {
+func (c Cursor) OnButton(cb func(dev InputDevice, time uint32, button uint32, state ButtonState)) Listener {
+	debugCheck(unsafe.Pointer(c.p))
+	return man.add(c.p, &c.p.events.button, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_pointer_button_event)(data)
 		dev := InputDevice{p: &event.pointer.base}
 		cb(dev, uint32(event.time_msec), uint32(event.button), ButtonState(event.state))
 	})
 }
 
-func (c Cursor) OnAxis(cb func(dev InputDevice, time uint32, source AxisSource, orientation AxisOrientation, delta float64, deltaDiscrete int32)) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.axis, func(data unsafe.Pointer) {
+func (c Cursor) OnAxis(cb func(dev InputDevice, time uint32, source AxisSource, orientation AxisOrientation, delta float64, deltaDiscrete int32)) Listener {
+	debugCheck(unsafe.Pointer(c.p))
+	return man.add(c.p, &c.p.events.axis, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_pointer_axis_event)(data)
 		dev := InputDevice{p: &event.pointer.base}
 		cb(dev, uint32(event.time_msec), AxisSource(event.source), AxisOrientation(event.orientation), float64(event.delta), int32(event.delta_discrete))
 	})
 }
 
-func (c Cursor) OnFrame(cb func()) {
-	man.add(unsafe.Pointer(c.p), &c.p.events.frame, func(data unsafe.Pointer) {
+func (c Cursor) OnFrame(cb func()) Listener {
+	debugCheck(unsafe.Pointer(c.p))
+	return man.add(c.p, &c.p.events.frame, func(data unsafe.Pointer) {
 		cb()
 	})
 }
@@ -0,0 +1,196 @@
+package wlroots
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Debug checks catch two classes of bugs that otherwise end in memory
+// corruption: using a wrapper after the object it refers to has been
+// destroyed, and calling into wlroots from a thread other than the one running
+// the event loop. They are disabled by default as they add a map lookup to
+// every checked method call.
+//
+// Destroyed objects are only detected for objects this package keeps track of
+// (outputs, surfaces, input devices, ...), and only once all of their destroy
+// callbacks have run.
+//
+// The thread check starts once the event loop is first dispatched through
+// EventLoop.Dispatch or Display.Run, so setting up the compositor on another
+// thread beforehand is fine.
+
+var (
+	debugEnabled atomic.Bool
+
+	destroyed      = map[unsafe.Pointer]struct{}{}
+	destroyedMutex sync.RWMutex
+)
+
+// EnableDebugChecks enables or disables the use-after-destroy and thread
+// checks. When enabled, checked methods panic with a descriptive message
+// instead of touching freed memory or racing with the event loop.
+//
+// It should be called before NewDisplay, objects destroyed while the checks
+// are disabled are not recorded.
+func EnableDebugChecks(enabled bool) {
+	debugEnabled.Store(enabled)
+	if !enabled {
+		destroyedMutex.Lock()
+		clear(destroyed)
+		destroyedMutex.Unlock()
+	}
+}
+
+func debugDestroyed(p unsafe.Pointer) {
+	if !debugEnabled.Load() {
+		return
+	}
+	destroyedMutex.Lock()
+	destroyed[p] = struct{}{}
+	destroyedMutex.Unlock()
+}
+
+// debugAlive clears p from the set of destroyed objects, as the allocator may
+// hand out the same address to a new object. Every constructor and new_*
+// event wrapper must call it, unless it starts tracking the object right away.
+func debugAlive(p unsafe.Pointer) {
+	if !debugEnabled.Load() {
+		return
+	}
+	destroyedMutex.Lock()
+	delete(destroyed, p)
+	destroyedMutex.Unlock()
+}
+
+func debugCheck(p unsafe.Pointer) {
+	if !debugEnabled.Load() {
+		return
+	}
+
+	if owner := dispatcher.Load(); owner != 0 {
+		if tid := int64(unix.Gettid()); tid != owner {
+			panic(fmt.Sprintf("wlroots: %s called from thread %d, but the event loop runs on thread %d (use EventLoop.Post)", callerName(), tid, owner))
+		}
+	}
+
+	destroyedMutex.RLock()
+	_, found := destroyed[p]
+	destroyedMutex.RUnlock()
+	if found {
+		panic(fmt.Sprintf("wlroots: %s called on destroyed object %p", callerName(), p))
+	}
+}
+
+// Stats is a snapshot of the objects and listeners the package keeps track
+// of.
+type Stats struct {
+	Objects   int
+	Listeners int
+	Callbacks int
+
+	// Types breaks the numbers down by C type, e.g. "wlr_output".
+	Types map[string]*TypeStats
+}
+
+type TypeStats struct {
+	Objects   int
+	Listeners int
+
+	// Signals counts the callbacks per method they were registered with,
+	// e.g. "Output.OnFrame".
+	Signals map[string]int
+}
+
+// TrackedObject describes an object that still has listeners attached.
+type TrackedObject struct {
+	Type      string
+	Address   uintptr
+	Callbacks []string
+}
+
+// DebugStats returns the number of objects and listeners the package currently
+// keeps track of. After Display.Destroy, every count should be zero unless
+// objects were created that wlroots does not destroy along with the display.
+func DebugStats() Stats {
+	man.mutex.RLock()
+	defer man.mutex.RUnlock()
+
+	stats := Stats{Types: map[string]*TypeStats{}}
+	for _, ls := range man.objects {
+		if len(ls) == 0 {
+			continue
+		}
+		stats.Objects++
+		ts := stats.typeStats(ls[0].kind)
+		ts.Objects++
+
+		for _, l := range ls {
+			stats.Listeners++
+			stats.Callbacks += len(l.cbs)
+			ts := stats.typeStats(l.kind)
+			ts.Listeners++
+			for _, cb := range l.cbs {
+				ts.Signals[cb.name]++
+			}
+		}
+	}
+	return stats
+}
+
+func (s *Stats) typeStats(kind string) *TypeStats {
+	ts, found := s.Types[kind]
+	if !found {
+		ts = &TypeStats{Signals: map[string]int{}}
+		s.Types[kind] = ts
+	}
+	return ts
+}
+
+// TrackedObjects lists every object that still has listeners attached, sorted
+// by type and address.
+func TrackedObjects() []TrackedObject {
+	man.mutex.RLock()
+	var objs []TrackedObject
+	for p, ls := range man.objects {
+		if len(ls) == 0 {
+			continue
+		}
+		obj := TrackedObject{Type: ls[0].kind, Address: uintptr(p)}
+		for _, l := range ls {
+			for _, cb := range l.cbs {
+				obj.Callbacks = append(obj.Callbacks, cb.name)
+			}
+		}
+		objs = append(objs, obj)
+	}
+	man.mutex.RUnlock()
+
+	slices.SortFunc(objs, func(a, b TrackedObject) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Address, b.Address)
+	})
+	return objs
+}
+
+// DumpTrackedObjects writes a human readable list of TrackedObjects to w. It is
+// meant to find leaking listeners, e.g. after Display.Destroy.
+func DumpTrackedObjects(w io.Writer) error {
+	objs := TrackedObjects()
+	for _, obj := range objs {
+		if _, err := fmt.Fprintf(w, "%s %#x: %s\n", obj.Type, obj.Address, strings.Join(obj.Callbacks, ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d tracked objects\n", len(objs))
+	return err
+}
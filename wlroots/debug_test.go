@@ -0,0 +1,18 @@
+package wlroots
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDebugStatsAfterDestroy(t *testing.T) {
+	d := NewDisplay()
+	d.Destroy()
+
+	s := DebugStats()
+	if s.Objects != 0 || s.Listeners != 0 {
+		var buf bytes.Buffer
+		DumpTrackedObjects(&buf)
+		t.Fatalf("got %d objects and %d listeners after Display.Destroy, want none:\n%s", s.Objects, s.Listeners, buf.String())
+	}
+}
@@ -0,0 +1,348 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server libdrm
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdlib.h>
+// #include <drm_fourcc.h>
+// #include <xf86drm.h>
+// #include <wlr/interfaces/wlr_buffer.h>
+// #include <wlr/render/allocator.h>
+// #include <wlr/render/drm_format.h>
+// #include <wlr/render/wlr_renderer.h>
+// #include <wlr/types/wlr_output.h>
+import "C"
+
+// A few common DRM fourcc codes. See drm_fourcc.h for the full list.
+const (
+	DRMFormatARGB8888 uint32 = C.DRM_FORMAT_ARGB8888
+	DRMFormatXRGB8888 uint32 = C.DRM_FORMAT_XRGB8888
+	DRMFormatABGR8888 uint32 = C.DRM_FORMAT_ABGR8888
+	DRMFormatXBGR8888 uint32 = C.DRM_FORMAT_XBGR8888
+)
+
+const (
+	DRMFormatModInvalid uint64 = C.DRM_FORMAT_MOD_INVALID
+	DRMFormatModLinear  uint64 = C.DRM_FORMAT_MOD_LINEAR
+)
+
+/**
+ * Buffer capabilities.
+ *
+ * These bits indicate the features supported by a struct wlr_buffer. There is
+ * one bit per function in struct wlr_buffer_impl.
+ */
+type BufferCaps uint32
+
+const (
+	BufferCapDataPtr BufferCaps = C.WLR_BUFFER_CAP_DATA_PTR
+	BufferCapDMABuf  BufferCaps = C.WLR_BUFFER_CAP_DMABUF
+	BufferCapShm     BufferCaps = C.WLR_BUFFER_CAP_SHM
+)
+
+// bytesPerPixel holds the size of a pixel of the single-plane formats wlroots
+// renderers can upload.
+var bytesPerPixel = map[uint32]int{
+	C.DRM_FORMAT_R8:   1,
+	C.DRM_FORMAT_R16:  2,
+	C.DRM_FORMAT_RG88: 2,
+	C.DRM_FORMAT_GR88: 2,
+
+	C.DRM_FORMAT_RGB565:   2,
+	C.DRM_FORMAT_BGR565:   2,
+	C.DRM_FORMAT_XRGB4444: 2,
+	C.DRM_FORMAT_ARGB4444: 2,
+	C.DRM_FORMAT_XBGR4444: 2,
+	C.DRM_FORMAT_ABGR4444: 2,
+	C.DRM_FORMAT_RGBX4444: 2,
+	C.DRM_FORMAT_RGBA4444: 2,
+	C.DRM_FORMAT_BGRX4444: 2,
+	C.DRM_FORMAT_BGRA4444: 2,
+	C.DRM_FORMAT_XRGB1555: 2,
+	C.DRM_FORMAT_ARGB1555: 2,
+	C.DRM_FORMAT_XBGR1555: 2,
+	C.DRM_FORMAT_ABGR1555: 2,
+	C.DRM_FORMAT_RGBX5551: 2,
+	C.DRM_FORMAT_RGBA5551: 2,
+	C.DRM_FORMAT_BGRX5551: 2,
+	C.DRM_FORMAT_BGRA5551: 2,
+
+	C.DRM_FORMAT_RGB888: 3,
+	C.DRM_FORMAT_BGR888: 3,
+
+	C.DRM_FORMAT_XRGB8888:    4,
+	C.DRM_FORMAT_ARGB8888:    4,
+	C.DRM_FORMAT_XBGR8888:    4,
+	C.DRM_FORMAT_ABGR8888:    4,
+	C.DRM_FORMAT_RGBX8888:    4,
+	C.DRM_FORMAT_RGBA8888:    4,
+	C.DRM_FORMAT_BGRX8888:    4,
+	C.DRM_FORMAT_BGRA8888:    4,
+	C.DRM_FORMAT_XRGB2101010: 4,
+	C.DRM_FORMAT_ARGB2101010: 4,
+	C.DRM_FORMAT_XBGR2101010: 4,
+	C.DRM_FORMAT_ABGR2101010: 4,
+	C.DRM_FORMAT_RGBX1010102: 4,
+	C.DRM_FORMAT_RGBA1010102: 4,
+	C.DRM_FORMAT_BGRX1010102: 4,
+	C.DRM_FORMAT_BGRA1010102: 4,
+
+	C.DRM_FORMAT_XRGB16161616:  8,
+	C.DRM_FORMAT_ARGB16161616:  8,
+	C.DRM_FORMAT_XBGR16161616:  8,
+	C.DRM_FORMAT_ABGR16161616:  8,
+	C.DRM_FORMAT_XRGB16161616F: 8,
+	C.DRM_FORMAT_ARGB16161616F: 8,
+	C.DRM_FORMAT_XBGR16161616F: 8,
+	C.DRM_FORMAT_ABGR16161616F: 8,
+}
+
+// FormatName returns the name of a DRM fourcc code, e.g. "XRGB8888". Codes
+// unknown to libdrm are printed as their four characters.
+func FormatName(format uint32) string {
+	if name := C.drmGetFormatName(C.uint32_t(format)); name != nil {
+		defer C.free(unsafe.Pointer(name))
+		return C.GoString(name)
+	}
+
+	b := []byte{byte(format), byte(format >> 8), byte(format >> 16), byte(format >> 24)}
+	for i, c := range b {
+		if c < 0x20 || c > 0x7e {
+			b[i] = '?'
+		}
+	}
+	return fmt.Sprintf("%s (0x%08x)", strings.TrimRight(string(b), " "), format)
+}
+
+// ModifierName returns the name of a DRM format modifier, e.g. "LINEAR".
+// Modifiers unknown to libdrm are printed in hexadecimal.
+func ModifierName(modifier uint64) string {
+	if name := C.drmGetFormatModifierName(C.uint64_t(modifier)); name != nil {
+		defer C.free(unsafe.Pointer(name))
+		return C.GoString(name)
+	}
+	return fmt.Sprintf("0x%016x", modifier)
+}
+
+/**
+ * A DRM format with a set of modifiers.
+ *
+ * A modifier describes the memory layout of the buffer, such as tiling. The
+ * special modifier DRMFormatModInvalid indicates an implicit modifier, chosen
+ * by the driver.
+ */
+type DRMFormat struct {
+	Format    uint32
+	Modifiers []uint64
+}
+
+func (f DRMFormat) Has(modifier uint64) bool {
+	if !slices.IsSorted(f.Modifiers) {
+		return slices.Contains(f.Modifiers, modifier)
+	}
+	_, found := slices.BinarySearch(f.Modifiers, modifier)
+	return found
+}
+
+func (f DRMFormat) String() string {
+	mods := make([]string, len(f.Modifiers))
+	for i, m := range f.Modifiers {
+		mods[i] = ModifierName(m)
+	}
+	return FormatName(f.Format) + " [" + strings.Join(mods, ", ") + "]"
+}
+
+/**
+ * A set of DRM formats and modifiers, sorted by format, each with sorted
+ * modifiers.
+ *
+ * This is used to describe the supported format + modifier combinations. For
+ * instance, backends will report the set they can display, and renderers will
+ * report the set they can render to.
+ *
+ * Sets built by hand should go through NewDRMFormatSet. The methods accept
+ * unsorted sets as well, but have to sort a copy on every call.
+ */
+type DRMFormatSet []DRMFormat
+
+// NewDRMFormatSet builds a set from formats, which may be in any order and
+// contain the same format or modifier more than once. The formats are copied.
+func NewDRMFormatSet(formats ...DRMFormat) DRMFormatSet {
+	set := make(DRMFormatSet, 0, len(formats))
+	for _, f := range formats {
+		set = append(set, DRMFormat{Format: f.Format, Modifiers: slices.Clone(f.Modifiers)})
+	}
+	slices.SortStableFunc(set, compareFormats)
+
+	// merge the modifiers of duplicate formats into the first entry
+	out := set[:0]
+	for _, f := range set {
+		if n := len(out); n > 0 && out[n-1].Format == f.Format {
+			out[n-1].Modifiers = append(out[n-1].Modifiers, f.Modifiers...)
+			continue
+		}
+		out = append(out, f)
+	}
+	for i := range out {
+		slices.Sort(out[i].Modifiers)
+		out[i].Modifiers = slices.Compact(out[i].Modifiers)
+	}
+	return out
+}
+
+func drmFormatSetFromC(p *C.struct_wlr_drm_format_set) DRMFormatSet {
+	if p == nil || p.len == 0 {
+		return DRMFormatSet{}
+	}
+
+	formats := unsafe.Slice(p.formats, p.len)
+	set := make(DRMFormatSet, len(formats))
+	for i, f := range formats {
+		set[i].Format = uint32(f.format)
+		if f.len > 0 {
+			set[i].Modifiers = unsafe.Slice((*uint64)(unsafe.Pointer(f.modifiers)), f.len)
+		}
+	}
+	return NewDRMFormatSet(set...)
+}
+
+func compareFormats(a, b DRMFormat) int {
+	return cmp.Compare(a.Format, b.Format)
+}
+
+// normalized returns s itself if it is sorted and free of duplicates, and a
+// normalized copy otherwise.
+func (s DRMFormatSet) normalized() DRMFormatSet {
+	for i, f := range s {
+		if i > 0 && s[i-1].Format >= f.Format {
+			return NewDRMFormatSet(s...)
+		}
+		for j := 1; j < len(f.Modifiers); j++ {
+			if f.Modifiers[j-1] >= f.Modifiers[j] {
+				return NewDRMFormatSet(s...)
+			}
+		}
+	}
+	return s
+}
+
+// Get returns the entry for format, if the set contains it.
+func (s DRMFormatSet) Get(format uint32) (DRMFormat, bool) {
+	return s.normalized().get(format)
+}
+
+// get is Get for a normalized set.
+func (s DRMFormatSet) get(format uint32) (DRMFormat, bool) {
+	i, found := slices.BinarySearchFunc(s, format, func(f DRMFormat, format uint32) int {
+		return cmp.Compare(f.Format, format)
+	})
+	if !found {
+		return DRMFormat{}, false
+	}
+	return s[i], true
+}
+
+// Has reports whether the set contains the format + modifier combination.
+func (s DRMFormatSet) Has(format uint32, modifier uint64) bool {
+	f, found := s.Get(format)
+	return found && f.Has(modifier)
+}
+
+// Formats returns the fourcc codes in the set, without their modifiers.
+func (s DRMFormatSet) Formats() []uint32 {
+	s = s.normalized()
+	formats := make([]uint32, len(s))
+	for i, f := range s {
+		formats[i] = f.Format
+	}
+	return formats
+}
+
+/**
+ * Intersect two DRM format sets, returning the format + modifier combinations
+ * present in both. Formats without a modifier in common are left out.
+ */
+func (s DRMFormatSet) Intersect(other DRMFormatSet) DRMFormatSet {
+	s, other = s.normalized(), other.normalized()
+
+	out := DRMFormatSet{}
+	for _, f := range s {
+		o, found := other.get(f.Format)
+		if !found {
+			continue
+		}
+		var mods []uint64
+		for _, m := range f.Modifiers {
+			if o.Has(m) {
+				mods = append(mods, m)
+			}
+		}
+		if len(mods) > 0 {
+			out = append(out, DRMFormat{Format: f.Format, Modifiers: mods})
+		}
+	}
+	return out
+}
+
+/**
+ * Unions DRM format sets, returning every format + modifier combination
+ * present in either.
+ */
+func (s DRMFormatSet) Union(other DRMFormatSet) DRMFormatSet {
+	return NewDRMFormatSet(append(slices.Clone(s), other...)...)
+}
+
+func (s DRMFormatSet) String() string {
+	formats := make([]string, len(s))
+	for i, f := range s {
+		formats[i] = f.String()
+	}
+	return strings.Join(formats, "\n")
+}
+
+/**
+ * Get the formats supporting sampling usage.
+ *
+ * The buffer capabilities must be a single capability.
+ */
+func (r Renderer) TextureFormats(bufferCaps BufferCaps) DRMFormatSet {
+	debugCheck(unsafe.Pointer(r.p))
+	return drmFormatSetFromC(C.wlr_renderer_get_texture_formats(r.p, C.uint32_t(bufferCaps)))
+}
+
+// BufferCaps returns the kinds of buffers the allocator creates.
+func (s Allocator) BufferCaps() BufferCaps {
+	return BufferCaps(s.p.buffer_caps)
+}
+
+/**
+ * Get the set of DRM formats suitable for the primary buffer, assuming a
+ * buffer with the capabilities of the output's allocator.
+ *
+ * restricted is false if the backend accepts any format, in which case the
+ * set is empty.
+ */
+func (o Output) PrimaryFormats() (formats DRMFormatSet, restricted bool) {
+	debugCheck(unsafe.Pointer(o.p))
+	caps := BufferCapDataPtr | BufferCapDMABuf | BufferCapShm
+	if o.p.allocator != nil {
+		caps = BufferCaps(o.p.allocator.buffer_caps)
+	}
+	p := C.wlr_output_get_primary_formats(o.p, C.uint32_t(caps))
+	if p == nil {
+		return DRMFormatSet{}, false
+	}
+	return drmFormatSetFromC(p), true
+}
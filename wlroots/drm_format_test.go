@@ -0,0 +1,194 @@
+package wlroots
+
+import (
+	"slices"
+	"testing"
+)
+
+func equalFormatSets(a, b DRMFormatSet) bool {
+	return slices.EqualFunc(a, b, func(a, b DRMFormat) bool {
+		return a.Format == b.Format && slices.Equal(a.Modifiers, b.Modifiers)
+	})
+}
+
+func TestNewDRMFormatSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []DRMFormat
+		want    DRMFormatSet
+	}{
+		{
+			name: "empty",
+			want: DRMFormatSet{},
+		},
+		{
+			name: "sorted",
+			formats: []DRMFormat{
+				{Format: 1, Modifiers: []uint64{1, 2}},
+				{Format: 2, Modifiers: []uint64{3}},
+			},
+			want: DRMFormatSet{
+				{Format: 1, Modifiers: []uint64{1, 2}},
+				{Format: 2, Modifiers: []uint64{3}},
+			},
+		},
+		{
+			name: "unsorted",
+			formats: []DRMFormat{
+				{Format: 2, Modifiers: []uint64{3}},
+				{Format: 1, Modifiers: []uint64{2, 1}},
+			},
+			want: DRMFormatSet{
+				{Format: 1, Modifiers: []uint64{1, 2}},
+				{Format: 2, Modifiers: []uint64{3}},
+			},
+		},
+		{
+			name: "duplicates",
+			formats: []DRMFormat{
+				{Format: 1, Modifiers: []uint64{2, 2}},
+				{Format: 2},
+				{Format: 1, Modifiers: []uint64{1, 2}},
+			},
+			want: DRMFormatSet{
+				{Format: 1, Modifiers: []uint64{1, 2}},
+				{Format: 2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NewDRMFormatSet(test.formats...); !equalFormatSets(got, test.want) {
+				t.Errorf("NewDRMFormatSet(%v) = %v, want %v", test.formats, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDRMFormatSetHas(t *testing.T) {
+	sorted := DRMFormatSet{
+		{Format: 1, Modifiers: []uint64{1, 2}},
+		{Format: 3, Modifiers: []uint64{DRMFormatModLinear, DRMFormatModInvalid}},
+	}
+	unsorted := DRMFormatSet{
+		{Format: 3, Modifiers: []uint64{DRMFormatModInvalid, DRMFormatModLinear}},
+		{Format: 1, Modifiers: []uint64{2}},
+		{Format: 1, Modifiers: []uint64{1}},
+	}
+
+	tests := []struct {
+		name     string
+		set      DRMFormatSet
+		format   uint32
+		modifier uint64
+		want     bool
+	}{
+		{"empty", nil, 1, 1, false},
+		{"present", sorted, 1, 2, true},
+		{"missing modifier", sorted, 1, 3, false},
+		{"missing format", sorted, 2, 1, false},
+		{"invalid modifier", sorted, 3, DRMFormatModInvalid, true},
+		{"unsorted formats", unsorted, 3, DRMFormatModLinear, true},
+		{"unsorted modifiers", unsorted, 3, DRMFormatModInvalid, true},
+		{"duplicate format", unsorted, 1, 2, true},
+		{"unsorted missing", unsorted, 2, 1, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.set.Has(test.format, test.modifier); got != test.want {
+				t.Errorf("Has(%d, %d) = %v, want %v", test.format, test.modifier, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDRMFormatSetIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b DRMFormatSet
+		want DRMFormatSet
+	}{
+		{
+			name: "empty",
+			a:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}},
+			want: DRMFormatSet{},
+		},
+		{
+			name: "common modifiers",
+			a:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1, 2, 3}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{2, 3, 4}}},
+			want: DRMFormatSet{{Format: 1, Modifiers: []uint64{2, 3}}},
+		},
+		{
+			name: "no common modifier",
+			a:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}, {Format: 2, Modifiers: []uint64{1}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{2}}, {Format: 2, Modifiers: []uint64{1}}},
+			want: DRMFormatSet{{Format: 2, Modifiers: []uint64{1}}},
+		},
+		{
+			name: "unsorted",
+			a:    DRMFormatSet{{Format: 2, Modifiers: []uint64{3, 1}}, {Format: 1, Modifiers: []uint64{1}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}, {Format: 2, Modifiers: []uint64{1, 3}}},
+			want: DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}, {Format: 2, Modifiers: []uint64{1, 3}}},
+		},
+		{
+			name: "duplicates",
+			a:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}, {Format: 1, Modifiers: []uint64{2}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{2, 2}}},
+			want: DRMFormatSet{{Format: 1, Modifiers: []uint64{2}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.a.Intersect(test.b); !equalFormatSets(got, test.want) {
+				t.Errorf("%v.Intersect(%v) = %v, want %v", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDRMFormatSetUnion(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b DRMFormatSet
+		want DRMFormatSet
+	}{
+		{
+			name: "empty",
+			want: DRMFormatSet{},
+		},
+		{
+			name: "disjoint",
+			a:    DRMFormatSet{{Format: 2, Modifiers: []uint64{1}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}},
+			want: DRMFormatSet{{Format: 1, Modifiers: []uint64{1}}, {Format: 2, Modifiers: []uint64{1}}},
+		},
+		{
+			name: "overlapping",
+			a:    DRMFormatSet{{Format: 1, Modifiers: []uint64{1, 3}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{2, 3}}},
+			want: DRMFormatSet{{Format: 1, Modifiers: []uint64{1, 2, 3}}},
+		},
+		{
+			name: "unsorted with duplicates",
+			a:    DRMFormatSet{{Format: 2, Modifiers: []uint64{2, 1}}, {Format: 1}, {Format: 2, Modifiers: []uint64{1}}},
+			b:    DRMFormatSet{{Format: 1, Modifiers: []uint64{4, 4}}},
+			want: DRMFormatSet{{Format: 1, Modifiers: []uint64{4}}, {Format: 2, Modifiers: []uint64{1, 2}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := slices.Clone(test.a)
+			if got := test.a.Union(test.b); !equalFormatSets(got, test.want) {
+				t.Errorf("%v.Union(%v) = %v, want %v", test.a, test.b, got, test.want)
+			}
+			if !equalFormatSets(test.a, a) {
+				t.Errorf("Union modified its receiver: %v, was %v", test.a, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,520 @@
+package wlroots
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdint.h>
+// #include <wayland-server-core.h>
+//
+// void _wl_listener_cb(struct wl_listener *listener, void *data);
+//
+// static inline int _wl_event_loop_fd_cb(int fd, uint32_t mask, void *data) {
+//		_wl_listener_cb(data, &mask);
+//		return 0;
+// }
+//
+// static inline int _wl_event_loop_timer_cb(void *data) {
+//		_wl_listener_cb(data, NULL);
+//		return 0;
+// }
+//
+// static inline void _wl_event_loop_idle_cb(void *data) {
+//		_wl_listener_cb(data, NULL);
+// }
+//
+// static inline struct wl_event_source *_wl_event_loop_add_fd(struct wl_event_loop *loop, int fd, uint32_t mask, struct wl_listener *listener) {
+//		return wl_event_loop_add_fd(loop, fd, mask, &_wl_event_loop_fd_cb, listener);
+// }
+//
+// static inline struct wl_event_source *_wl_event_loop_add_timer(struct wl_event_loop *loop, struct wl_listener *listener) {
+//		return wl_event_loop_add_timer(loop, &_wl_event_loop_timer_cb, listener);
+// }
+//
+// static inline struct wl_event_source *_wl_event_loop_add_idle(struct wl_event_loop *loop, struct wl_listener *listener) {
+//		return wl_event_loop_add_idle(loop, &_wl_event_loop_idle_cb, listener);
+// }
+//
+import "C"
+
+var (
+	ErrEventLoopClosed     = errors.New("event loop has been destroyed")
+	ErrEventLoopNotRunning = errors.New("event loop is not being dispatched")
+)
+
+type EventMask uint32
+
+const (
+	EventReadable EventMask = C.WL_EVENT_READABLE
+	EventWritable EventMask = C.WL_EVENT_WRITABLE
+	EventHangup   EventMask = C.WL_EVENT_HANGUP
+	EventError    EventMask = C.WL_EVENT_ERROR
+)
+
+type EventLoop struct {
+	p *C.struct_wl_event_loop
+}
+
+// loopDestroy holds the destroy listener that initEventLoop adds to each event
+// loop. OnDestroy callbacks share it, so that it can free every listener of the
+// loop without pulling one out from under wl_event_loop_destroy.
+var loopDestroy sync.Map
+
+func (evl EventLoop) OnDestroy(cb func(EventLoop)) Listener {
+	fn := func(data unsafe.Pointer) {
+		cb(evl)
+	}
+	if root, found := loopDestroy.Load(evl.p); found {
+		if l, ok := man.addTo(root.(Listener), fn); ok {
+			return l
+		}
+	}
+
+	l := man.add(evl.p, nil, fn)
+	C.wl_event_loop_add_destroy_listener(evl.p, l.l.p)
+	return l
+}
+
+func (evl EventLoop) Fd() uintptr {
+	return uintptr(C.wl_event_loop_get_fd(evl.p))
+}
+
+// Dispatch waits up to timeout for events and runs their callbacks. A
+// negative timeout waits forever.
+//
+// The event loop must always be dispatched from the same OS thread, so the
+// goroutine doing it should call runtime.LockOSThread first.
+func (evl EventLoop) Dispatch(timeout time.Duration) error {
+	setDispatcher()
+
+	var d int
+	if timeout >= 0 {
+		d = int(timeout / time.Millisecond)
+	} else {
+		d = -1
+	}
+	if ret, err := C.wl_event_loop_dispatch(evl.p, C.int(d)); ret < 0 {
+		// epoll_wait is interrupted by the signals the Go runtime uses for
+		// preemption, this is not an error
+		if err == unix.EINTR {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Post schedules fn to be run on the thread that dispatches the event loop.
+// Unlike every other method in this package, it is safe to call Post from any
+// goroutine. It returns ErrEventLoopClosed if the event loop has been
+// destroyed, in which case fn will never run.
+func (evl EventLoop) Post(fn func()) error {
+	p := lookupPoster(evl)
+	if p == nil {
+		return ErrEventLoopClosed
+	}
+	return p.post(fn)
+}
+
+// Invoke is like Post, but waits for fn to return. When called from the thread
+// dispatching the event loop, fn is run immediately. If fn panics and the panic
+// policy is PanicPolicyRecover, Invoke returns the *CallbackError.
+//
+// Invoke returns ErrEventLoopNotRunning until the event loop has been
+// dispatched for the first time, as it would block until then. Code setting up
+// the compositor should call fn directly instead.
+func (evl EventLoop) Invoke(fn func()) error {
+	p := lookupPoster(evl)
+	if p == nil {
+		return ErrEventLoopClosed
+	}
+	switch dispatcher.Load() {
+	case 0:
+		return ErrEventLoopNotRunning
+	case int64(unix.Gettid()):
+		fn()
+		return nil
+	}
+
+	var cbErr *CallbackError
+	done := make(chan struct{})
+	err := p.post(func() {
+		defer close(done)
+		cbErr = guard("EventLoop.Invoke", uintptr(unsafe.Pointer(evl.p)), 0, fn)
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		if cbErr != nil {
+			return cbErr
+		}
+		return nil
+	case <-p.closed:
+		return ErrEventLoopClosed
+	}
+}
+
+// AddFD calls cb whenever fd becomes ready for any of the events in mask.
+// EventHangup and EventError are always reported, even if they are not part of
+// mask. The file descriptor is not closed when the source is removed.
+func (evl EventLoop) AddFD(fd int, mask EventMask, cb func(fd int, mask EventMask)) (EventSource, error) {
+	return evl.addSource(func(l Listener) *C.struct_wl_event_source {
+		return C._wl_event_loop_add_fd(evl.p, C.int(fd), C.uint32_t(mask), l.l.p)
+	}, func(data unsafe.Pointer) {
+		cb(fd, EventMask(*(*C.uint32_t)(data)))
+	})
+}
+
+// AddTimer creates a timer that calls cb once it expires. The timer starts out
+// disarmed, use EventSource.Update to arm it.
+func (evl EventLoop) AddTimer(cb func()) (EventSource, error) {
+	return evl.addSource(func(l Listener) *C.struct_wl_event_source {
+		return C._wl_event_loop_add_timer(evl.p, l.l.p)
+	}, func(unsafe.Pointer) {
+		cb()
+	})
+}
+
+// AddSignal calls cb on the event loop thread whenever the process receives
+// sig.
+//
+// wl_event_loop_add_signal relies on blocking the signal for the calling
+// thread, which does not work with the Go runtime moving goroutines between
+// threads. Instead, the signal is received through os/signal and forwarded to
+// the event loop.
+func (evl EventLoop) AddSignal(sig os.Signal, cb func(os.Signal)) (EventSource, error) {
+	fd, err := unix.Eventfd(0, unix.EFD_CLOEXEC|unix.EFD_NONBLOCK)
+	if err != nil {
+		return EventSource{}, err
+	}
+
+	s, err := evl.AddFD(fd, EventReadable, func(fd int, _ EventMask) {
+		var buf [8]byte
+		unix.Read(fd, buf[:])
+		cb(sig)
+	})
+	if err != nil {
+		unix.Close(fd)
+		return EventSource{}, err
+	}
+
+	var (
+		mutex  sync.Mutex
+		closed bool
+	)
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				mutex.Lock()
+				if !closed {
+					writeEventfd(fd)
+				}
+				mutex.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	sources.setCleanup(s, func() {
+		signal.Stop(ch)
+		close(done)
+
+		mutex.Lock()
+		closed = true
+		unix.Close(fd)
+		mutex.Unlock()
+	})
+	return s, nil
+}
+
+// AddIdle calls cb once the event loop has nothing else to do. The source is
+// removed automatically after cb has run.
+func (evl EventLoop) AddIdle(cb func()) (EventSource, error) {
+	var s EventSource
+	s, err := evl.addSource(func(l Listener) *C.struct_wl_event_source {
+		return C._wl_event_loop_add_idle(evl.p, l.l.p)
+	}, func(unsafe.Pointer) {
+		// libwayland frees idle sources by itself once they have fired
+		if es := sources.forget(s); es != nil {
+			defer es.release()
+		}
+		cb()
+	})
+	return s, err
+}
+
+func (evl EventLoop) addSource(create func(Listener) *C.struct_wl_event_source, cb listenerCallback) (EventSource, error) {
+	// the listener is only used to route the callback through
+	// _wl_listener_cb, it is never added to a signal
+	l := man.add(evl.p, nil, cb)
+	p := create(l)
+	if p == nil {
+		l.Remove()
+		return EventSource{}, errors.New("can't add event source")
+	}
+
+	s := EventSource{p: p}
+	sources.add(s, &eventSource{loop: evl.p, l: l})
+	return s, nil
+}
+
+// EventSource is a timer, file descriptor, signal or idle source added to an
+// EventLoop.
+type EventSource struct {
+	p *C.struct_wl_event_source
+}
+
+// Update arms a timer source to expire after delay, with millisecond
+// precision. A delay of zero disarms the timer.
+func (s EventSource) Update(delay time.Duration) error {
+	ms := delay.Milliseconds()
+	if delay > 0 && ms == 0 {
+		ms = 1
+	}
+	if C.wl_event_source_timer_update(s.p, C.int(ms)) != 0 {
+		return errors.New("can't update timer")
+	}
+	return nil
+}
+
+// UpdateMask changes the events an fd source is interested in.
+func (s EventSource) UpdateMask(mask EventMask) error {
+	if C.wl_event_source_fd_update(s.p, C.uint32_t(mask)) != 0 {
+		return errors.New("can't update event mask")
+	}
+	return nil
+}
+
+// Check marks the source as having an event to dispatch again after the
+// current dispatch, even if no new events are reported for it. This is useful
+// for sources that read only part of the available data in one go.
+func (s EventSource) Check() {
+	C.wl_event_source_check(s.p)
+}
+
+// Remove removes the source from its event loop. It is a no-op if the source
+// has already been removed.
+func (s EventSource) Remove() {
+	es := sources.forget(s)
+	if es == nil {
+		return
+	}
+	C.wl_event_source_remove(s.p)
+	es.release()
+}
+
+func (s EventSource) Nil() bool {
+	return s.p == nil
+}
+
+type eventSource struct {
+	loop    *C.struct_wl_event_loop
+	l       Listener
+	cleanup func()
+}
+
+func (es *eventSource) release() {
+	es.l.Remove()
+	if es.cleanup != nil {
+		es.cleanup()
+	}
+}
+
+type eventSourceTable struct {
+	mutex   sync.Mutex
+	sources map[*C.struct_wl_event_source]*eventSource
+}
+
+var sources = &eventSourceTable{
+	sources: map[*C.struct_wl_event_source]*eventSource{},
+}
+
+func (t *eventSourceTable) add(s EventSource, es *eventSource) {
+	t.mutex.Lock()
+	t.sources[s.p] = es
+	t.mutex.Unlock()
+}
+
+func (t *eventSourceTable) setCleanup(s EventSource, cleanup func()) {
+	t.mutex.Lock()
+	if es, found := t.sources[s.p]; found {
+		es.cleanup = cleanup
+	}
+	t.mutex.Unlock()
+}
+
+func (t *eventSourceTable) forget(s EventSource) *eventSource {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	es := t.sources[s.p]
+	delete(t.sources, s.p)
+	return es
+}
+
+// removeAll removes every source that is still attached to the event loop.
+// libwayland does not do this by itself when the loop is destroyed.
+func (t *eventSourceTable) removeAll(loop *C.struct_wl_event_loop) {
+	t.mutex.Lock()
+	var ps []*C.struct_wl_event_source
+	for p, es := range t.sources {
+		if es.loop == loop {
+			ps = append(ps, p)
+		}
+	}
+	t.mutex.Unlock()
+
+	for _, p := range ps {
+		EventSource{p: p}.Remove()
+	}
+}
+
+// poster is the queue behind EventLoop.Post. Other goroutines append to the
+// queue and wake up the event loop through an eventfd, which the event loop
+// then drains on its own thread.
+type poster struct {
+	mutex  sync.Mutex
+	fd     int
+	queue  []func()
+	closed chan struct{}
+}
+
+var (
+	posters      = map[*C.struct_wl_event_loop]*poster{}
+	postersMutex sync.RWMutex
+
+	// dispatcher is the thread that last started dispatching an event loop.
+	// Invoke uses it to run functions inline, the debug checks to catch calls
+	// from other threads.
+	dispatcher atomic.Int64
+)
+
+func lookupPoster(evl EventLoop) *poster {
+	postersMutex.RLock()
+	defer postersMutex.RUnlock()
+	return posters[evl.p]
+}
+
+// setDispatcher records the calling thread as the one dispatching the event
+// loop.
+func setDispatcher() {
+	dispatcher.Store(int64(unix.Gettid()))
+}
+
+// initEventLoop sets up Post for the given event loop and makes sure all
+// event sources are removed when it is destroyed.
+func initEventLoop(evl EventLoop) error {
+	root := man.addLast(evl.p, nil, func(unsafe.Pointer) {
+		loopDestroy.Delete(evl.p)
+		sources.removeAll(evl.p)
+		man.delete(unsafe.Pointer(evl.p))
+	})
+	C.wl_event_loop_add_destroy_listener(evl.p, root.l.p)
+	loopDestroy.Store(evl.p, root)
+
+	fd, err := unix.Eventfd(0, unix.EFD_CLOEXEC|unix.EFD_NONBLOCK)
+	if err != nil {
+		return err
+	}
+
+	p := &poster{
+		fd:     fd,
+		closed: make(chan struct{}),
+	}
+	s, err := evl.AddFD(fd, EventReadable, func(int, EventMask) {
+		p.drain()
+	})
+	if err != nil {
+		unix.Close(fd)
+		return err
+	}
+
+	postersMutex.Lock()
+	posters[evl.p] = p
+	postersMutex.Unlock()
+
+	sources.setCleanup(s, func() {
+		postersMutex.Lock()
+		delete(posters, evl.p)
+		postersMutex.Unlock()
+
+		p.mutex.Lock()
+		p.queue = nil
+		close(p.closed)
+		unix.Close(p.fd)
+		p.mutex.Unlock()
+	})
+
+	return nil
+}
+
+func (p *poster) post(fn func()) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	select {
+	case <-p.closed:
+		return ErrEventLoopClosed
+	default:
+	}
+
+	p.queue = append(p.queue, fn)
+	return writeEventfd(p.fd)
+}
+
+func (p *poster) drain() {
+	var buf [8]byte
+	unix.Read(p.fd, buf[:])
+
+	// if one of the functions panics, make sure the rest of the queue is not
+	// forgotten
+	defer func() {
+		p.mutex.Lock()
+		if len(p.queue) > 0 {
+			writeEventfd(p.fd)
+		}
+		p.mutex.Unlock()
+	}()
+
+	for {
+		p.mutex.Lock()
+		if len(p.queue) == 0 {
+			p.mutex.Unlock()
+			return
+		}
+		fn := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mutex.Unlock()
+
+		fn()
+	}
+}
+
+func writeEventfd(fd int) error {
+	var buf [8]byte
+	binary.NativeEndian.PutUint64(buf[:], 1)
+	_, err := unix.Write(fd, buf[:])
+	if err == unix.EAGAIN {
+		// the counter is saturated, so the event loop is bound to wake up
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/types/wlr_gamma_control_v1.h>
+// #include <wlr/types/wlr_output.h>
+import "C"
+
+/**
+ * Implementation of the wlr-gamma-control-unstable-v1 protocol, used by
+ * gammastep, wlsunset and similar tools to set gamma tables.
+ */
+type GammaControlManagerV1 struct {
+	p *C.struct_wlr_gamma_control_manager_v1
+}
+
+/**
+ * The gamma table a client requested for an output. The zero value stands
+ * for no client control, which resets the gamma table.
+ */
+type GammaControl struct {
+	p *C.struct_wlr_gamma_control_v1
+}
+
+func NewGammaControlManagerV1(display Display) GammaControlManagerV1 {
+	debugCheck(unsafe.Pointer(display.p))
+	p := C.wlr_gamma_control_manager_v1_create(display.p)
+	man.track(p, &p.events.destroy)
+	return GammaControlManagerV1{p: p}
+}
+
+func (m GammaControlManagerV1) OnDestroy(cb func(GammaControlManagerV1)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
+		cb(m)
+	})
+}
+
+/**
+ * Emitted when a client sets or resets the gamma table of an output. The
+ * control is Nil if the gamma table should be reset.
+ *
+ * The table should be applied on the next commit of the output, usually
+ * from the frame handler, with ApplyToOutputState. If the commit fails, the
+ * client should be notified with SendFailed.
+ */
+func (m GammaControlManagerV1) OnSetGamma(cb func(GammaControlManagerV1, Output, GammaControl)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.set_gamma, func(data unsafe.Pointer) {
+		event := (*C.struct_wlr_gamma_control_manager_v1_set_gamma_event)(data)
+		cb(m, Output{p: event.output}, GammaControl{p: event.control})
+	})
+}
+
+// Control returns the gamma control of the output, which is Nil if no client
+// controls its gamma.
+func (m GammaControlManagerV1) Control(output Output) GammaControl {
+	debugCheck(unsafe.Pointer(m.p))
+	return GammaControl{p: C.wlr_gamma_control_manager_v1_get_control(m.p, output.p)}
+}
+
+func (c GammaControl) Nil() bool {
+	return c.p == nil
+}
+
+// Output returns the output the gamma table is meant for.
+func (c GammaControl) Output() Output {
+	debugCheck(unsafe.Pointer(c.p))
+	return Output{p: c.p.output}
+}
+
+/**
+ * Sets the gamma table of the control on the output state, or resets it for
+ * a Nil control.
+ */
+func (c GammaControl) ApplyToOutputState(state OutputState) error {
+	debugCheck(unsafe.Pointer(c.p))
+	if !C.wlr_gamma_control_v1_apply(c.p, state.p) {
+		return errors.New("failed to apply gamma table")
+	}
+	return nil
+}
+
+/**
+ * Tells the client that its gamma table could not be applied. The control is
+ * destroyed and must not be used afterwards.
+ */
+func (c GammaControl) SendFailed() {
+	debugCheck(unsafe.Pointer(c.p))
+	if c.p != nil {
+		C.wlr_gamma_control_v1_send_failed_and_destroy(c.p)
+	}
+}
@@ -0,0 +1,138 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdbool.h>
+// #include <wayland-server-core.h>
+//
+// bool _wl_display_global_filter_cb(struct wl_client *client, struct wl_global *global, void *data);
+//
+// static inline bool _wl_display_global_filter(const struct wl_client *client, const struct wl_global *global, void *data) {
+//		return _wl_display_global_filter_cb((struct wl_client *)client, (struct wl_global *)global, data);
+// }
+//
+// static inline void _wl_display_set_global_filter(struct wl_display *display, bool enabled) {
+//		wl_display_set_global_filter(display, enabled ? &_wl_display_global_filter : NULL, display);
+// }
+import "C"
+
+// GlobalFilterFunc decides whether client may see and bind global.
+type GlobalFilterFunc func(client Client, global GlobalInfo) bool
+
+// GlobalInfo describes a global advertised by the display.
+type GlobalInfo struct {
+	// Interface is the name of the interface the global implements, e.g.
+	// "zwlr_screencopy_manager_v1".
+	Interface string
+	Version   int
+}
+
+// globalFilters maps each display to its GlobalFilterFunc.
+var globalFilters sync.Map
+
+// CapturePolicyFunc decides whether client may capture the contents of the
+// outputs.
+type CapturePolicyFunc func(client Client) bool
+
+type globalPolicy struct {
+	display *C.struct_wl_display
+	allow   func(Client) bool
+	// method that set the policy, for panic reports
+	name string
+}
+
+// globalPolicies maps globals to the policy restricting them, on top of the
+// display's filter.
+var globalPolicies sync.Map
+
+// setGlobalPolicy restricts global to the clients allowed by the policy. A nil
+// policy lifts the restriction.
+func setGlobalPolicy(display *C.struct_wl_display, global *C.struct_wl_global, allow func(Client) bool, name string) {
+	if allow == nil {
+		globalPolicies.Delete(global)
+		if _, found := globalFilters.Load(display); !found && !hasGlobalPolicies(display) {
+			C._wl_display_set_global_filter(display, false)
+		}
+		return
+	}
+	globalPolicies.Store(global, globalPolicy{display: display, allow: allow, name: name})
+	C._wl_display_set_global_filter(display, true)
+}
+
+func hasGlobalPolicies(display *C.struct_wl_display) bool {
+	found := false
+	globalPolicies.Range(func(_, v any) bool {
+		found = v.(globalPolicy).display == display
+		return !found
+	})
+	return found
+}
+
+//export _wl_display_global_filter_cb
+func _wl_display_global_filter_cb(client *C.struct_wl_client, global *C.struct_wl_global, data unsafe.Pointer) C.bool {
+	// a panicking filter or policy hides the global
+	allowed := false
+
+	if v, found := globalPolicies.Load(global); found {
+		policy := v.(globalPolicy)
+		guard(policy.name, uintptr(unsafe.Pointer(global)), 0, func() {
+			allowed = policy.allow(Client{p: client})
+		})
+		if !allowed {
+			return false
+		}
+	}
+
+	v, found := globalFilters.Load((*C.struct_wl_display)(data))
+	if !found {
+		return true
+	}
+
+	iface := C.wl_global_get_interface(global)
+	info := GlobalInfo{
+		Interface: C.GoString(iface.name),
+		Version:   int(C.wl_global_get_version(global)),
+	}
+	allowed = false
+	guard("Display.SetGlobalFilter", uintptr(data), 0, func() {
+		allowed = v.(GlobalFilterFunc)(Client{p: client}, info)
+	})
+	return C.bool(allowed)
+}
+
+/**
+ * Set a filter function for globals.
+ *
+ * Filtering a global hides it from a client: the client won't see it in the
+ * registry, and won't be able to bind to it. This can be used to restrict
+ * privileged protocols, such as screencopy or virtual keyboards, to trusted
+ * clients.
+ *
+ * The filter is called very often, it should be fast. Passing nil removes the
+ * filter, making all globals visible to all clients, except for those
+ * restricted by a capture policy.
+ *
+ * If the filter panics and the panic policy is PanicPolicyRecover, the global
+ * is hidden from the client.
+ */
+func (d Display) SetGlobalFilter(filter GlobalFilterFunc) {
+	if filter == nil {
+		globalFilters.Delete(d.p)
+		if !hasGlobalPolicies(d.p) {
+			C._wl_display_set_global_filter(d.p, false)
+		}
+		return
+	}
+	globalFilters.Store(d.p, filter)
+	C._wl_display_set_global_filter(d.p, true)
+}
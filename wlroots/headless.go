@@ -0,0 +1,63 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/backend/headless.h>
+import "C"
+
+/**
+ * A backend that doesn't render anything or receive any input, useful for
+ * testing and for running a compositor without a display. Outputs are created
+ * explicitly with AddOutput.
+ */
+type HeadlessBackend struct {
+	Backend
+}
+
+/**
+ * Creates a headless backend. A headless backend has no outputs or inputs by
+ * default.
+ */
+func NewHeadlessBackend(loop EventLoop) (HeadlessBackend, error) {
+	p := C.wlr_headless_backend_create(loop.p)
+	if p == nil {
+		return HeadlessBackend{}, errors.New("failed to create headless backend")
+	}
+	man.track(p, &p.events.destroy)
+	return HeadlessBackend{Backend{p: p}}, nil
+}
+
+/**
+ * Create a new headless output.
+ *
+ * The buffers presented on the output won't be displayed to the user. The
+ * backend emits the new output event once it has been started, or right away
+ * if it is started already.
+ */
+func (b HeadlessBackend) AddOutput(width int, height int) (Output, error) {
+	p := C.wlr_headless_add_output(b.p, C.uint(width), C.uint(height))
+	if p == nil {
+		return Output{}, errors.New("failed to add headless output")
+	}
+	return wrapOutput(unsafe.Pointer(p)), nil
+}
+
+func (b Backend) IsHeadless() bool {
+	debugCheck(unsafe.Pointer(b.p))
+	return bool(C.wlr_backend_is_headless(b.p))
+}
+
+func (o Output) IsHeadless() bool {
+	debugCheck(unsafe.Pointer(o.p))
+	return bool(C.wlr_output_is_headless(o.p))
+}
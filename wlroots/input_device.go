@@ -60,12 +60,26 @@ type InputDevice struct {
 	p *C.struct_wlr_input_device
 }
 
-func (d InputDevice) OnDestroy(cb func(InputDevice)) {
-	man.add(unsafe.Pointer(d.p), &d.p.events.destroy, func(unsafe.Pointer) {
+func (d InputDevice) OnDestroy(cb func(InputDevice)) Listener {
+	debugCheck(unsafe.Pointer(d.p))
+	return man.add(d.p, &d.p.events.destroy, func(unsafe.Pointer) {
 		cb(d)
 	})
 }
 
+// SetUserData attaches an arbitrary Go value to the input device until it is
+// destroyed.
+func (d InputDevice) SetUserData(v any) {
+	debugCheck(unsafe.Pointer(d.p))
+	man.setUserData(d.p, &d.p.events.destroy, v)
+}
+
+// UserData returns the value set with SetUserData, or nil.
+func (d InputDevice) UserData() any {
+	debugCheck(unsafe.Pointer(d.p))
+	return man.getUserData(unsafe.Pointer(d.p))
+}
+
 func (d InputDevice) Type() InputDeviceType { return InputDeviceType(d.p._type) }
 func (d InputDevice) Name() string          { return C.GoString(d.p.name) }
 
@@ -80,11 +94,13 @@ func validateInputDeviceType(d InputDevice, fn string, req InputDeviceType) {
 }
 
 func (d InputDevice) Keyboard() Keyboard {
+	debugCheck(unsafe.Pointer(d.p))
 	validateInputDeviceType(d, "Keyboard", InputDeviceTypeKeyboard)
 	p := *(*unsafe.Pointer)(unsafe.Pointer(&d.p))
 	return Keyboard{p: (*C.struct_wlr_keyboard)(p)}
 }
 
 func wrapInputDevice(p unsafe.Pointer) InputDevice {
+	debugAlive(p)
 	return InputDevice{p: (*C.struct_wlr_input_device)(p)}
 }
@@ -56,47 +56,57 @@ type Keyboard struct {
 }
 
 func (k Keyboard) SetKeymap(keymap xkb.Keymap) {
+	debugCheck(unsafe.Pointer(k.p))
 	C.wlr_keyboard_set_keymap(k.p, (*C.struct_xkb_keymap)(keymap.Ptr()))
 }
 
 func (k Keyboard) RepeatInfo() (rate int32, delay int32) {
+	debugCheck(unsafe.Pointer(k.p))
 	return int32(k.p.repeat_info.rate), int32(k.p.repeat_info.delay)
 }
 
 func (k Keyboard) SetRepeatInfo(rate int32, delay int32) {
+	debugCheck(unsafe.Pointer(k.p))
 	C.wlr_keyboard_set_repeat_info(k.p, C.int32_t(rate), C.int32_t(delay))
 }
 
 func (k Keyboard) Base() InputDevice {
+	debugCheck(unsafe.Pointer(k.p))
 	return InputDevice{p: &k.p.base}
 }
 
 func (k Keyboard) XKBState() xkb.State {
+	debugCheck(unsafe.Pointer(k.p))
 	return xkb.WrapState(unsafe.Pointer(k.p.xkb_state))
 }
 
 func (k Keyboard) Leds() int {
+	debugCheck(unsafe.Pointer(k.p))
 	return int(k.p.leds)
 }
 
 func (k Keyboard) Modifiers() KeyboardModifier {
+	debugCheck(unsafe.Pointer(k.p))
 	return KeyboardModifier(C.wlr_keyboard_get_modifiers(k.p))
 }
 
-func (k Keyboard) OnModifiers(cb func(keyboard Keyboard)) {
-	man.add(unsafe.Pointer(k.p), &k.p.events.modifiers, func(data unsafe.Pointer) {
+func (k Keyboard) OnModifiers(cb func(keyboard Keyboard)) Listener {
+	debugCheck(unsafe.Pointer(k.p))
+	return man.add(k.p, &k.p.events.modifiers, func(data unsafe.Pointer) {
 		cb(k)
 	})
 }
 
-func (k Keyboard) OnDestroy(cb func(keyboard Keyboard)) {
-	man.add(unsafe.Pointer(k.p), &k.p.base.events.destroy, func(data unsafe.Pointer) {
+func (k Keyboard) OnDestroy(cb func(keyboard Keyboard)) Listener {
+	debugCheck(unsafe.Pointer(k.p))
+	return man.add(k.p, &k.p.base.events.destroy, func(data unsafe.Pointer) {
 		cb(k)
 	})
 }
 
-func (k Keyboard) OnKey(cb func(keyboard Keyboard, time uint32, keyCode uint32, updateState bool, state KeyState)) {
-	man.add(unsafe.Pointer(k.p), &k.p.events.key, func(data unsafe.Pointer) {
+func (k Keyboard) OnKey(cb func(keyboard Keyboard, time uint32, keyCode uint32, updateState bool, state KeyState)) Listener {
+	debugCheck(unsafe.Pointer(k.p))
+	return man.add(k.p, &k.p.events.key, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_keyboard_key_event)(data)
 		cb(k, uint32(event.time_msec), uint32(event.keycode), bool(event.update_state), KeyState(event.state))
 	})
@@ -1,8 +1,9 @@
 package wlroots
 
 import (
+	"reflect"
+	"strings"
 	"sync"
-	"time"
 	"unsafe"
 )
 
@@ -15,11 +16,10 @@ import (
 // destroyed. So, we need to keep track of all objects (and their listeners)
 // manually and listen for the destroy signal to be able to free everything.
 //
-// 3 (TODO). As we're keeping track of all objects anyway, we might as well
-// store a Go pointer to the wrapper struct along with them in order to be able
-// to pass the same Go pointer through callbacks every time. This will also
-// allow calling runtime.SetFinalizer on some of them to clean them up early
-// when the GC notices it has gone out of scope.
+// 3. As we're keeping track of all objects anyway, we might as well use the
+// same table to attach arbitrary Go values to them (see SetUserData). Wrappers
+// are plain values holding the C pointer, so two wrappers of the same object
+// always compare equal and lead to the same user data.
 //
 // Send help.
 
@@ -36,31 +36,6 @@ import (
 //
 import "C"
 
-type EventLoop struct {
-	p *C.struct_wl_event_loop
-}
-
-func (evl EventLoop) OnDestroy(cb func(EventLoop)) {
-	l := man.add(unsafe.Pointer(evl.p), nil, func(data unsafe.Pointer) {
-		cb(evl)
-	})
-	C.wl_event_loop_add_destroy_listener(evl.p, l.p)
-}
-
-func (evl EventLoop) Fd() uintptr {
-	return uintptr(C.wl_event_loop_get_fd(evl.p))
-}
-
-func (evl EventLoop) Dispatch(timeout time.Duration) {
-	var d int
-	if timeout >= 0 {
-		d = int(timeout / time.Millisecond)
-	} else {
-		d = -1
-	}
-	C.wl_event_loop_dispatch(evl.p, C.int(d))
-}
-
 type (
 	listenerCallback func(data unsafe.Pointer)
 )
@@ -69,18 +44,49 @@ type manager struct {
 	mutex     sync.RWMutex
 	objects   map[unsafe.Pointer][]*listener
 	listeners map[*C.struct_wl_listener]*listener
+	userData  map[unsafe.Pointer]*userData
 }
 
 type listener struct {
-	p   *C.struct_wl_listener
-	s   *C.struct_wl_signal
-	cbs []listenerCallback
+	p    *C.struct_wl_listener
+	s    *C.struct_wl_signal
+	obj  unsafe.Pointer
+	kind string
+	cbs  []*callback
+}
+
+type callback struct {
+	fn   listenerCallback
+	name string
+
+	// last callbacks always run after all regular callbacks of a listener
+	last bool
+}
+
+type userData struct {
+	v any
+	l Listener
+}
+
+// Listener is a handle to a callback registered through one of the On*
+// methods. It can be used to detach the callback before the object it was
+// registered on is destroyed.
+type Listener struct {
+	l  *listener
+	cb *callback
+}
+
+// Remove detaches the callback. It is safe to call Remove more than once, from
+// within the callback itself, or after the object has been destroyed.
+func (l Listener) Remove() {
+	man.remove(l)
 }
 
 var (
 	man = &manager{
 		objects:   map[unsafe.Pointer][]*listener{},
 		listeners: map[*C.struct_wl_listener]*listener{},
+		userData:  map[unsafe.Pointer]*userData{},
 	}
 )
 
@@ -88,23 +94,70 @@ var (
 func _wl_listener_cb(listener *C.struct_wl_listener, data unsafe.Pointer) {
 	man.mutex.RLock()
 	l := man.listeners[listener]
+	var cbs []*callback
+	if l != nil {
+		cbs = l.cbs
+	}
 	man.mutex.RUnlock()
-	for _, cb := range l.cbs {
-		cb(data)
+	for _, cb := range cbs {
+		runCallback(l, cb, data)
+	}
+}
+
+// objectOf returns the address of obj, which is either a pointer to a C struct
+// or an unsafe.Pointer, along with the name of the C type it points to.
+func objectOf(obj any) (unsafe.Pointer, string) {
+	if p, ok := obj.(unsafe.Pointer); ok {
+		return p, "unknown"
+	}
+
+	v := reflect.ValueOf(obj)
+	kind := strings.TrimPrefix(v.Type().Elem().Name(), "_Ctype_")
+	return v.UnsafePointer(), strings.TrimPrefix(kind, "struct_")
+}
+
+func (m *manager) add(obj any, signal *C.struct_wl_signal, cb listenerCallback) Listener {
+	return m.addCallback(obj, signal, &callback{fn: cb, name: callerName()})
+}
+
+// addLast is like add, but the callback runs after every callback that is
+// added to the same signal later on.
+func (m *manager) addLast(obj any, signal *C.struct_wl_signal, cb listenerCallback) Listener {
+	return m.addCallback(obj, signal, &callback{fn: cb, name: callerName(), last: true})
+}
+
+// addTo adds cb to the listener behind h instead of creating a new one. This is
+// needed for signals that can only be reached by adding a listener, such as
+// the destroy signal of wl_event_loop. It returns false if the listener is
+// gone.
+func (m *manager) addTo(h Listener, cb listenerCallback) (Listener, bool) {
+	c := &callback{fn: cb, name: callerName()}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if h.l == nil || m.listeners[h.l.p] != h.l {
+		return Listener{}, false
 	}
+	h.l.cbs = insertCallback(h.l.cbs, c)
+	return Listener{l: h.l, cb: c}, true
 }
 
-func (m *manager) add(p unsafe.Pointer, signal *C.struct_wl_signal, cb listenerCallback) *listener {
+func (m *manager) addCallback(obj any, signal *C.struct_wl_signal, c *callback) Listener {
+	p, kind := objectOf(obj)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	debugAlive(p)
+
 	// if a listener for this object and signal already exists, add the callback
 	// to the existing listener
 	if signal != nil {
 		for _, l := range m.objects[p] {
 			if l.s != nil && l.s == signal {
-				l.cbs = append(l.cbs, cb)
-				return l
+				l.cbs = insertCallback(l.cbs, c)
+				return Listener{l: l, cb: c}
 			}
 		}
 	}
@@ -113,17 +166,81 @@ func (m *manager) add(p unsafe.Pointer, signal *C.struct_wl_signal, cb listenerC
 	C._wl_listener_set_cb(lp)
 	if signal != nil {
 		C.wl_signal_add((*C.struct_wl_signal)(signal), lp)
+	} else {
+		// make sure removing the listener is safe even if it never ends up in
+		// a list
+		C.wl_list_init(&lp.link)
 	}
 
 	l := &listener{
-		p:   lp,
-		s:   signal,
-		cbs: []listenerCallback{cb},
+		p:    lp,
+		s:    signal,
+		obj:  p,
+		kind: kind,
+		cbs:  []*callback{c},
 	}
 	m.listeners[lp] = l
 	m.objects[p] = append(m.objects[p], l)
 
-	return l
+	return Listener{l: l, cb: c}
+}
+
+func insertCallback(cbs []*callback, c *callback) []*callback {
+	i := len(cbs)
+	if !c.last {
+		for i > 0 && cbs[i-1].last {
+			i--
+		}
+	}
+
+	// never modify cbs in place, _wl_listener_cb may be iterating over it
+	res := make([]*callback, 0, len(cbs)+1)
+	res = append(res, cbs[:i]...)
+	res = append(res, c)
+	return append(res, cbs[i:]...)
+}
+
+func (m *manager) remove(h Listener) {
+	if h.l == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// the listener is already gone if the object has been destroyed
+	l := h.l
+	if m.listeners[l.p] != l {
+		return
+	}
+
+	cbs := make([]*callback, 0, len(l.cbs))
+	for _, cb := range l.cbs {
+		if cb != h.cb {
+			cbs = append(cbs, cb)
+		}
+	}
+	l.cbs = cbs
+	if len(cbs) > 0 {
+		return
+	}
+
+	// this was the last callback, get rid of the wl_listener as well
+	delete(m.listeners, l.p)
+	C.wl_list_remove(&l.p.link)
+	C.free(unsafe.Pointer(l.p))
+
+	ls := make([]*listener, 0, len(m.objects[l.obj]))
+	for _, ol := range m.objects[l.obj] {
+		if ol != l {
+			ls = append(ls, ol)
+		}
+	}
+	if len(ls) > 0 {
+		m.objects[l.obj] = ls
+	} else {
+		delete(m.objects, l.obj)
+	}
 }
 
 func (m *manager) has(p unsafe.Pointer) bool {
@@ -148,8 +265,55 @@ func (m *manager) delete(p unsafe.Pointer) {
 	}
 
 	delete(m.objects, p)
+	delete(m.userData, p)
+	debugDestroyed(p)
+}
+
+func (m *manager) track(obj any, destroySignal *C.struct_wl_signal) {
+	p, _ := objectOf(obj)
+	m.addLast(obj, destroySignal, func(data unsafe.Pointer) { m.delete(p) })
 }
 
-func (m *manager) track(p unsafe.Pointer, destroySignal *C.struct_wl_signal) {
-	m.add(p, destroySignal, func(data unsafe.Pointer) { m.delete(p) })
+// setUserData attaches v to the object at p. The value is dropped once the
+// destroy signal of the object has been emitted, after all other destroy
+// callbacks have run.
+func (m *manager) setUserData(obj any, destroySignal *C.struct_wl_signal, v any) {
+	p, _ := objectOf(obj)
+
+	m.mutex.Lock()
+	ud, found := m.userData[p]
+	if found && v != nil {
+		ud.v = v
+	} else if found {
+		delete(m.userData, p)
+	}
+	m.mutex.Unlock()
+
+	if found {
+		if v == nil {
+			ud.l.Remove()
+		}
+		return
+	} else if v == nil {
+		return
+	}
+
+	// the object may not be tracked otherwise, so get rid of all of its
+	// listeners along with the data
+	ud = &userData{v: v}
+	ud.l = m.addLast(obj, destroySignal, func(unsafe.Pointer) {
+		m.delete(p)
+	})
+	m.mutex.Lock()
+	m.userData[p] = ud
+	m.mutex.Unlock()
+}
+
+func (m *manager) getUserData(p unsafe.Pointer) any {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if ud, found := m.userData[p]; found {
+		return ud.v
+	}
+	return nil
 }
@@ -0,0 +1,184 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdint.h>
+// #include <stdlib.h>
+// #include <wlr/backend/multi.h>
+//
+// void _wlr_multi_for_each_backend_cb(struct wlr_backend *backend, void *data);
+// static inline void _wlr_multi_for_each_backend(struct wlr_backend *multi, uintptr_t handle) {
+//		wlr_multi_for_each_backend(multi, &_wlr_multi_for_each_backend_cb, (void *)handle);
+// }
+import "C"
+
+// The backend_add and backend_remove signals live in struct wlr_multi_backend,
+// which wlroots doesn't expose. Instead, the wrapper keeps signals of its own,
+// emitted whenever a backend is added or removed through MultiBackend, or when
+// such a backend is destroyed.
+type multiBackendState struct {
+	add    *C.struct_wl_signal
+	remove *C.struct_wl_signal
+
+	// destroy listeners of the sub-backends
+	children map[*C.struct_wlr_backend]Listener
+}
+
+var (
+	multiBackends      = map[*C.struct_wlr_backend]*multiBackendState{}
+	multiBackendsMutex sync.Mutex
+)
+
+/**
+ * A backend that combines several backends, e.g. a headless backend for
+ * virtual outputs and a Wayland backend for nested windows. Events of all
+ * sub-backends are forwarded.
+ */
+type MultiBackend struct {
+	Backend
+}
+
+/**
+ * Creates a multi-backend. Multi-backends wrap an arbitrary number of backends
+ * and aggregate their new_output/new_input signals.
+ */
+func NewMultiBackend(loop EventLoop) (MultiBackend, error) {
+	p := C.wlr_multi_backend_create(loop.p)
+	if p == nil {
+		return MultiBackend{}, errors.New("failed to create multi backend")
+	}
+	man.track(p, &p.events.destroy)
+	return MultiBackend{Backend{p: p}}, nil
+}
+
+func (b Backend) IsMulti() bool {
+	debugCheck(unsafe.Pointer(b.p))
+	return bool(C.wlr_backend_is_multi(b.p))
+}
+
+// AsMulti returns b as a MultiBackend, if it is one. Autocreated backends
+// always are.
+func (b Backend) AsMulti() (MultiBackend, bool) {
+	if !b.IsMulti() {
+		return MultiBackend{}, false
+	}
+	return MultiBackend{b}, true
+}
+
+func (m MultiBackend) state() *multiBackendState {
+	multiBackendsMutex.Lock()
+	defer multiBackendsMutex.Unlock()
+
+	if s, found := multiBackends[m.p]; found {
+		return s
+	}
+
+	s := &multiBackendState{
+		add:      (*C.struct_wl_signal)(C.calloc(C.sizeof_struct_wl_signal, 1)),
+		remove:   (*C.struct_wl_signal)(C.calloc(C.sizeof_struct_wl_signal, 1)),
+		children: map[*C.struct_wlr_backend]Listener{},
+	}
+	C.wl_signal_init(s.add)
+	C.wl_signal_init(s.remove)
+	multiBackends[m.p] = s
+
+	// runs after the listeners on the signals have been freed by the
+	// manager
+	man.addLast(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
+		multiBackendsMutex.Lock()
+		delete(multiBackends, m.p)
+		multiBackendsMutex.Unlock()
+
+		for _, l := range s.children {
+			l.Remove()
+		}
+		C.free(unsafe.Pointer(s.add))
+		C.free(unsafe.Pointer(s.remove))
+	})
+	return s
+}
+
+/**
+ * Adds the given backend to the multi backend. This should be done before the
+ * new backend is started.
+ */
+func (m MultiBackend) AddBackend(b Backend) error {
+	debugCheck(unsafe.Pointer(m.p))
+	if !C.wlr_multi_backend_add(m.p, b.p) {
+		return errors.New("can't add backend to multi backend")
+	}
+
+	s := m.state()
+	if _, found := s.children[b.p]; found {
+		return nil
+	}
+	s.children[b.p] = man.add(b.p, &b.p.events.destroy, func(unsafe.Pointer) {
+		// wlroots removes destroyed backends by itself
+		s.children[b.p].Remove()
+		delete(s.children, b.p)
+		C.wl_signal_emit_mutable(s.remove, unsafe.Pointer(b.p))
+	})
+	C.wl_signal_emit_mutable(s.add, unsafe.Pointer(b.p))
+	return nil
+}
+
+func (m MultiBackend) RemoveBackend(b Backend) {
+	debugCheck(unsafe.Pointer(m.p))
+	C.wlr_multi_backend_remove(m.p, b.p)
+
+	s := m.state()
+	if l, found := s.children[b.p]; found {
+		l.Remove()
+		delete(s.children, b.p)
+		C.wl_signal_emit_mutable(s.remove, unsafe.Pointer(b.p))
+	}
+}
+
+func (m MultiBackend) IsEmpty() bool {
+	debugCheck(unsafe.Pointer(m.p))
+	return bool(C.wlr_multi_is_empty(m.p))
+}
+
+//export _wlr_multi_for_each_backend_cb
+func _wlr_multi_for_each_backend_cb(backend *C.struct_wlr_backend, data unsafe.Pointer) {
+	cb := cgo.Handle(uintptr(data)).Value().(func(Backend))
+	cb(Backend{p: backend})
+}
+
+// ForEachBackend calls cb for every sub-backend, including the ones wlroots
+// added by itself.
+func (m MultiBackend) ForEachBackend(cb func(Backend)) {
+	debugCheck(unsafe.Pointer(m.p))
+	h := cgo.NewHandle(cb)
+	defer h.Delete()
+	C._wlr_multi_for_each_backend(m.p, C.uintptr_t(h))
+}
+
+// OnBackendAdd is called when a backend is added with AddBackend.
+func (m MultiBackend) OnBackendAdd(cb func(Backend)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, m.state().add, func(data unsafe.Pointer) {
+		cb(Backend{p: (*C.struct_wlr_backend)(data)})
+	})
+}
+
+// OnBackendRemove is called when a backend added with AddBackend is removed
+// again, either with RemoveBackend or because it was destroyed.
+func (m MultiBackend) OnBackendRemove(cb func(Backend)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, m.state().remove, func(data unsafe.Pointer) {
+		cb(Backend{p: (*C.struct_wlr_backend)(data)})
+	})
+}
@@ -7,6 +7,7 @@ package wlroots
 
 import (
 	"errors"
+	"image"
 	"unsafe"
 )
 
@@ -33,36 +34,108 @@ type Output struct {
 }
 
 func wrapOutput(p unsafe.Pointer) Output {
+	debugAlive(p)
 	return Output{p: (*C.struct_wlr_output)(p)}
 }
 
+func (o Output) Nil() bool {
+	return o.p == nil
+}
+
 func (o Output) Name() string {
+	debugCheck(unsafe.Pointer(o.p))
 	return C.GoString(o.p.name)
 }
 
 func (o Output) Scale() float32 {
+	debugCheck(unsafe.Pointer(o.p))
 	return float32(o.p.scale)
 }
 
-func (o Output) OnFrame(cb func(Output)) {
-	man.add(unsafe.Pointer(o.p), &o.p.events.frame, func(data unsafe.Pointer) {
+func (o Output) Width() int {
+	debugCheck(unsafe.Pointer(o.p))
+	return int(o.p.width)
+}
+
+func (o Output) Height() int {
+	debugCheck(unsafe.Pointer(o.p))
+	return int(o.p.height)
+}
+
+// CurrentMode returns the mode the output is using. It is Nil if the output
+// doesn't support modes or runs with a custom mode.
+func (o Output) CurrentMode() OutputMode {
+	debugCheck(unsafe.Pointer(o.p))
+	return OutputMode{p: o.p.current_mode}
+}
+
+func (o Output) Transform() OutputTransform {
+	debugCheck(unsafe.Pointer(o.p))
+	return OutputTransform(o.p.transform)
+}
+
+func (o Output) Subpixel() OutputSubpixel {
+	debugCheck(unsafe.Pointer(o.p))
+	return OutputSubpixel(o.p.subpixel)
+}
+
+func (o Output) AdaptiveSyncStatus() OutputSdaptiveSyncStatus {
+	debugCheck(unsafe.Pointer(o.p))
+	return OutputSdaptiveSyncStatus(o.p.adaptive_sync_status)
+}
+
+/**
+ * Returns the size of the gamma table of the output, or zero if it doesn't
+ * support gamma correction.
+ */
+func (o Output) GammaSize() int {
+	debugCheck(unsafe.Pointer(o.p))
+	return int(C.wlr_output_get_gamma_size(o.p))
+}
+
+// RenderFormat returns the DRM fourcc code of the buffers rendered for this
+// output.
+func (o Output) RenderFormat() uint32 {
+	debugCheck(unsafe.Pointer(o.p))
+	return uint32(o.p.render_format)
+}
+
+func (o Output) OnFrame(cb func(Output)) Listener {
+	debugCheck(unsafe.Pointer(o.p))
+	return man.add(o.p, &o.p.events.frame, func(data unsafe.Pointer) {
 		cb(o)
 	})
 }
 
-func (o Output) OnRequestState(cb func(Output, OutputState)) {
-	man.add(unsafe.Pointer(o.p), &o.p.events.request_state, func(data unsafe.Pointer) {
+func (o Output) OnRequestState(cb func(Output, OutputState)) Listener {
+	debugCheck(unsafe.Pointer(o.p))
+	return man.add(o.p, &o.p.events.request_state, func(data unsafe.Pointer) {
 		cb(o, OutputState{p: (*C.struct_wlr_output_state)(data)})
 	})
 }
 
-func (o Output) OnDestroy(cb func(Output)) {
-	man.add(unsafe.Pointer(o.p), &o.p.events.destroy, func(data unsafe.Pointer) {
+func (o Output) OnDestroy(cb func(Output)) Listener {
+	debugCheck(unsafe.Pointer(o.p))
+	return man.add(o.p, &o.p.events.destroy, func(data unsafe.Pointer) {
 		cb(o)
 	})
 }
 
+// SetUserData attaches an arbitrary Go value to the output. It is released
+// after the output's destroy callbacks have run.
+func (o Output) SetUserData(v any) {
+	debugCheck(unsafe.Pointer(o.p))
+	man.setUserData(o.p, &o.p.events.destroy, v)
+}
+
+// UserData returns the value set with SetUserData, or nil.
+func (o Output) UserData() any {
+	debugCheck(unsafe.Pointer(o.p))
+	return man.getUserData(unsafe.Pointer(o.p))
+}
+
 func (o Output) RenderSoftwareCursors(pass RenderPass) {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_add_software_cursors_to_render_pass(o.p, pass.p, nil)
 }
 
@@ -70,6 +143,7 @@ func (o Output) RenderSoftwareCursors(pass RenderPass) {
  * Computes the transformed output resolution.
  */
 func (o Output) TransformedResolution() (int, int) {
+	debugCheck(unsafe.Pointer(o.p))
 	var width, height C.int
 	C.wlr_output_transformed_resolution(o.p, &width, &height)
 	return int(width), int(height)
@@ -79,6 +153,7 @@ func (o Output) TransformedResolution() (int, int) {
  * Computes the transformed and scaled output resolution.
  */
 func (o Output) EffectiveResolution() (int, int) {
+	debugCheck(unsafe.Pointer(o.p))
 	var width, height C.int
 	C.wlr_output_effective_resolution(o.p, &width, &height)
 	return int(width), int(height)
@@ -98,6 +173,7 @@ func (o Output) EffectiveResolution() (int, int) {
  * committed with. A NULL state indicates no change.
  */
 func (o Output) BeginRenderPass(state OutputState) (RenderPass, error) {
+	debugCheck(unsafe.Pointer(o.p))
 	pass := C.wlr_output_begin_render_pass(o.p, state.p, nil, nil)
 	if pass == nil {
 		return RenderPass{}, errors.New("can't begin render pass")
@@ -112,10 +188,12 @@ func (o Output) BeginRenderPass(state OutputState) (RenderPass, error) {
  * This is intended to be used by wl_output add-on interfaces.
  */
 func (o Output) ScheduleDone() {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_schedule_done(o.p)
 }
 
 func (o Output) Destroy() {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_destroy(o.p)
 }
 
@@ -126,6 +204,7 @@ func (o Output) Destroy() {
  * output.
  */
 func (o Output) TestState(s OutputState) bool {
+	debugCheck(unsafe.Pointer(o.p))
 	return bool(C.wlr_output_test_state(o.p, s.p))
 }
 
@@ -138,6 +217,7 @@ func (o Output) TestState(s OutputState) bool {
  * has been committed.
  */
 func (o Output) CommitState(s OutputState) bool {
+	debugCheck(unsafe.Pointer(o.p))
 	return bool(C.wlr_output_commit_state(o.p, s.p))
 }
 
@@ -146,10 +226,12 @@ func (o Output) CommitState(s OutputState) bool {
  * it is a no-op.
  */
 func (o Output) ScheduleFrame() {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_schedule_frame(o.p)
 }
 
 func (o Output) Modes() []OutputMode {
+	debugCheck(unsafe.Pointer(o.p))
 	// TODO: figure out what to do with this ridiculous for loop
 	// perhaps this can be refactored into a less ugly hack that uses reflection
 	var modes []OutputMode
@@ -166,6 +248,7 @@ func (o Output) Modes() []OutputMode {
  * modes, returns NULL.
  */
 func (o Output) PreferredMode() (OutputMode, error) {
+	debugCheck(unsafe.Pointer(o.p))
 	mode := C.wlr_output_preferred_mode(o.p)
 	if mode == nil {
 		return OutputMode{}, errors.New("no preferred mode")
@@ -184,30 +267,37 @@ func (o Output) PreferredMode() (OutputMode, error) {
  * For more details, see the protocol documentation for wl_output.name.
  */
 func (o Output) SetName(name string) {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_set_name(o.p, C.CString(name))
 }
 
 func (o Output) SetDescription(desc string) {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_set_description(o.p, C.CString(desc))
 }
 
 func (o Output) Enabled() bool {
+	debugCheck(unsafe.Pointer(o.p))
 	return bool(o.p.enabled)
 }
 
 func (o Output) Refresh() int {
+	debugCheck(unsafe.Pointer(o.p))
 	return int(o.p.refresh)
 }
 
 func (o Output) CreateGlobal(d Display) {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_create_global(o.p, d.p)
 }
 
 func (o Output) DestroyGlobal() {
+	debugCheck(unsafe.Pointer(o.p))
 	C.wlr_output_destroy_global(o.p)
 }
 
 func (o Output) SetTitle(title string) error {
+	debugCheck(unsafe.Pointer(o.p))
 	if C.wlr_output_is_wl(o.p) {
 		C.wlr_wl_output_set_title(o.p, C.CString(title))
 	} else if C.wlr_output_is_x11(o.p) {
@@ -231,6 +321,7 @@ func (o Output) SetTitle(title string) error {
  * output's backend. Returns false otherwise.
  */
 func (o Output) InitRender(a Allocator, r Renderer) bool {
+	debugCheck(unsafe.Pointer(o.p))
 	return bool(C.wlr_output_init_render(o.p, a.p, r.p))
 }
 
@@ -257,6 +348,92 @@ func (os OutputState) SetMode(mode OutputMode) {
 	C.wlr_output_state_set_mode(os.p, mode.p)
 }
 
+/**
+ * Sets a custom mode, for backends that aren't restricted to a list of
+ * modes. refresh is in mHz and may be zero.
+ */
+func (os OutputState) SetCustomMode(width int, height int, refresh int) {
+	C.wlr_output_state_set_custom_mode(os.p, C.int32_t(width), C.int32_t(height), C.int32_t(refresh))
+}
+
+func (os OutputState) SetScale(scale float32) {
+	C.wlr_output_state_set_scale(os.p, C.float(scale))
+}
+
+func (os OutputState) SetTransform(transform OutputTransform) {
+	C.wlr_output_state_set_transform(os.p, C.enum_wl_output_transform(transform))
+}
+
+func (os OutputState) SetAdaptiveSyncEnabled(enabled bool) {
+	C.wlr_output_state_set_adaptive_sync_enabled(os.p, C.bool(enabled))
+}
+
+/**
+ * Set the render format, as a DRM fourcc code. The default is XRGB8888.
+ */
+func (os OutputState) SetRenderFormat(format uint32) {
+	C.wlr_output_state_set_render_format(os.p, C.uint32_t(format))
+}
+
+func (os OutputState) SetSubpixel(subpixel OutputSubpixel) {
+	C.wlr_output_state_set_subpixel(os.p, C.enum_wl_output_subpixel(subpixel))
+}
+
+/**
+ * Sets the gamma table for an output. r, g and b are gamma ramps for red, green
+ * and blue and must have the same length. Empty ramps reset the gamma table.
+ *
+ * The gamma table is copied into the state.
+ */
+func (os OutputState) SetGammaLUT(r []uint16, g []uint16, b []uint16) error {
+	if len(r) != len(g) || len(r) != len(b) {
+		return errors.New("gamma ramps differ in size")
+	}
+	if len(r) == 0 {
+		C.wlr_output_state_set_gamma_lut(os.p, 0, nil, nil, nil)
+		return nil
+	}
+	if !C.wlr_output_state_set_gamma_lut(os.p, C.size_t(len(r)),
+		(*C.uint16_t)(&r[0]), (*C.uint16_t)(&g[0]), (*C.uint16_t)(&b[0])) {
+		return errors.New("failed to set gamma table")
+	}
+	return nil
+}
+
+/**
+ * Sets the damage region for an output. This is used as a hint to the backend
+ * and can be used to reduce power consumption or increase performance on some
+ * devices.
+ *
+ * The rectangles are in output buffer-local coordinates.
+ */
+func (os OutputState) SetDamage(damage ...image.Rectangle) {
+	boxes := make([]C.pixman_box32_t, 0, len(damage))
+	for _, r := range damage {
+		if r.Empty() {
+			continue
+		}
+		boxes = append(boxes, C.pixman_box32_t{
+			x1: C.int32_t(r.Min.X), y1: C.int32_t(r.Min.Y),
+			x2: C.int32_t(r.Max.X), y2: C.int32_t(r.Max.Y),
+		})
+	}
+
+	var region C.pixman_region32_t
+	if len(boxes) > 0 {
+		C.pixman_region32_init_rects(&region, &boxes[0], C.int(len(boxes)))
+	} else {
+		C.pixman_region32_init(&region)
+	}
+	C.wlr_output_state_set_damage(os.p, &region)
+	C.pixman_region32_fini(&region)
+}
+
+// Committed returns the fields set on the state.
+func (os OutputState) Committed() OutputStateField {
+	return OutputStateField(os.p.committed)
+}
+
 func (os OutputState) Finish() {
 	C.wlr_output_state_finish(os.p)
 }
@@ -265,6 +442,10 @@ type OutputMode struct {
 	p *C.struct_wlr_output_mode
 }
 
+func (m OutputMode) Nil() bool {
+	return m.p == nil
+}
+
 func (m OutputMode) Width() int {
 	return int(m.p.width)
 }
@@ -318,3 +499,27 @@ const (
 	OutputSdaptiveSync_Disabled OutputSdaptiveSyncStatus = C.WLR_OUTPUT_ADAPTIVE_SYNC_DISABLED
 	OutputSdaptiveSync_Enabled  OutputSdaptiveSyncStatus = C.WLR_OUTPUT_ADAPTIVE_SYNC_ENABLED
 )
+
+type OutputTransform uint32
+
+const (
+	OutputTransformNormal     OutputTransform = C.WL_OUTPUT_TRANSFORM_NORMAL
+	OutputTransform90         OutputTransform = C.WL_OUTPUT_TRANSFORM_90
+	OutputTransform180        OutputTransform = C.WL_OUTPUT_TRANSFORM_180
+	OutputTransform270        OutputTransform = C.WL_OUTPUT_TRANSFORM_270
+	OutputTransformFlipped    OutputTransform = C.WL_OUTPUT_TRANSFORM_FLIPPED
+	OutputTransformFlipped90  OutputTransform = C.WL_OUTPUT_TRANSFORM_FLIPPED_90
+	OutputTransformFlipped180 OutputTransform = C.WL_OUTPUT_TRANSFORM_FLIPPED_180
+	OutputTransformFlipped270 OutputTransform = C.WL_OUTPUT_TRANSFORM_FLIPPED_270
+)
+
+type OutputSubpixel uint32
+
+const (
+	OutputSubpixelUnknown       OutputSubpixel = C.WL_OUTPUT_SUBPIXEL_UNKNOWN
+	OutputSubpixelNone          OutputSubpixel = C.WL_OUTPUT_SUBPIXEL_NONE
+	OutputSubpixelHorizontalRGB OutputSubpixel = C.WL_OUTPUT_SUBPIXEL_HORIZONTAL_RGB
+	OutputSubpixelHorizontalBGR OutputSubpixel = C.WL_OUTPUT_SUBPIXEL_HORIZONTAL_BGR
+	OutputSubpixelVerticalRGB   OutputSubpixel = C.WL_OUTPUT_SUBPIXEL_VERTICAL_RGB
+	OutputSubpixelVerticalBGR   OutputSubpixel = C.WL_OUTPUT_SUBPIXEL_VERTICAL_BGR
+)
@@ -0,0 +1,248 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/types/wlr_output_layout.h>
+// #include <wlr/util/box.h>
+import "C"
+
+/**
+ * Helper to arrange outputs in a 2D coordinate space. The output effective
+ * resolution is used, see wlr_output_effective_resolution().
+ *
+ * Outputs added to the output layout are automatically exposed to clients (see
+ * wlr_output_create_global()). They are no longer exposed when removed from the
+ * layout.
+ */
+type OutputLayout struct {
+	p *C.struct_wlr_output_layout
+}
+
+/**
+ * An output in a struct wlr_output_layout.
+ */
+type OutputLayoutOutput struct {
+	p *C.struct_wlr_output_layout_output
+}
+
+type Direction uint32
+
+const (
+	DirectionUp    Direction = C.WLR_DIRECTION_UP
+	DirectionDown  Direction = C.WLR_DIRECTION_DOWN
+	DirectionLeft  Direction = C.WLR_DIRECTION_LEFT
+	DirectionRight Direction = C.WLR_DIRECTION_RIGHT
+)
+
+func NewOutputLayout(d Display) OutputLayout {
+	return OutputLayoutCreate(d)
+}
+
+func OutputLayoutCreate(d Display) OutputLayout {
+	debugCheck(unsafe.Pointer(d.p))
+	p := C.wlr_output_layout_create(d.p)
+	man.track(p, &p.events.destroy)
+	return OutputLayout{p: p}
+}
+
+func (l OutputLayout) Destroy() {
+	debugCheck(unsafe.Pointer(l.p))
+	C.wlr_output_layout_destroy(l.p)
+}
+
+func (l OutputLayout) OnDestroy(cb func(OutputLayout)) Listener {
+	debugCheck(unsafe.Pointer(l.p))
+	return man.add(l.p, &l.p.events.destroy, func(unsafe.Pointer) {
+		cb(l)
+	})
+}
+
+// OnAdd is called when an output is added to the layout.
+func (l OutputLayout) OnAdd(cb func(OutputLayout, OutputLayoutOutput)) Listener {
+	debugCheck(unsafe.Pointer(l.p))
+	return man.add(l.p, &l.p.events.add, func(data unsafe.Pointer) {
+		cb(l, OutputLayoutOutput{p: (*C.struct_wlr_output_layout_output)(data)})
+	})
+}
+
+// OnChange is called whenever the arrangement changes: outputs being added,
+// removed, moved, or changing their mode, scale or transform.
+func (l OutputLayout) OnChange(cb func(OutputLayout)) Listener {
+	debugCheck(unsafe.Pointer(l.p))
+	return man.add(l.p, &l.p.events.change, func(unsafe.Pointer) {
+		cb(l)
+	})
+}
+
+/**
+ * Add the output to the layout at the specified coordinates. If the output is
+ * already a part of the output layout, it will become manually configured and
+ * will be moved to the specified coordinates.
+ */
+func (l OutputLayout) Add(output Output, x int, y int) (OutputLayoutOutput, error) {
+	debugCheck(unsafe.Pointer(l.p))
+	p := C.wlr_output_layout_add(l.p, output.p, C.int(x), C.int(y))
+	if p == nil {
+		return OutputLayoutOutput{}, errors.New("failed to add output to layout")
+	}
+	return OutputLayoutOutput{p: p}, nil
+}
+
+/**
+ * Add the output to the layout as automatically configured. This will place
+ * the output in a sensible location in the layout. The coordinates of
+ * the output in the layout will be adjusted dynamically when the layout
+ * changes. If the output is already a part of the layout, it will become
+ * automatically configured.
+ */
+func (l OutputLayout) AddOutputAuto(output Output) OutputLayoutOutput {
+	debugCheck(unsafe.Pointer(l.p))
+	p := C.wlr_output_layout_add_auto(l.p, output.p)
+	return OutputLayoutOutput{p: p}
+}
+
+/**
+ * Remove the output from the layout. If the output is already not a part of
+ * the layout, this function is a no-op.
+ */
+func (l OutputLayout) Remove(output Output) {
+	debugCheck(unsafe.Pointer(l.p))
+	C.wlr_output_layout_remove(l.p, output.p)
+}
+
+/**
+ * Get the output layout for the specified output. Nil if the output is not
+ * part of the layout.
+ */
+func (l OutputLayout) Get(output Output) OutputLayoutOutput {
+	debugCheck(unsafe.Pointer(l.p))
+	return OutputLayoutOutput{p: C.wlr_output_layout_get(l.p, output.p)}
+}
+
+// Outputs returns the outputs in the layout, in the order they were added.
+func (l OutputLayout) Outputs() []OutputLayoutOutput {
+	debugCheck(unsafe.Pointer(l.p))
+	var outputs []OutputLayoutOutput
+	var lo *C.struct_wlr_output_layout_output
+	offset := unsafe.Offsetof(lo.link)
+	for link := l.p.outputs.next; link != &l.p.outputs; link = link.next {
+		lo = (*C.struct_wlr_output_layout_output)(unsafe.Add(unsafe.Pointer(link), -int(offset)))
+		outputs = append(outputs, OutputLayoutOutput{p: lo})
+	}
+	return outputs
+}
+
+func (l OutputLayout) Coords(output Output) (x float64, y float64) {
+	debugCheck(unsafe.Pointer(l.p))
+	var ox, oy C.double
+	C.wlr_output_layout_output_coords(l.p, output.p, &ox, &oy)
+	return float64(ox), float64(oy)
+}
+
+/**
+ * Get the output at the specified layout coordinates. Nil if no output matches
+ * the coordinates.
+ */
+func (l OutputLayout) OutputAt(lx float64, ly float64) Output {
+	debugCheck(unsafe.Pointer(l.p))
+	return Output{p: C.wlr_output_layout_output_at(l.p, C.double(lx), C.double(ly))}
+}
+
+func (l OutputLayout) ContainsPoint(reference Output, lx int, ly int) bool {
+	debugCheck(unsafe.Pointer(l.p))
+	return bool(C.wlr_output_layout_contains_point(l.p, reference.p, C.int(lx), C.int(ly)))
+}
+
+/**
+ * Get the closest point on this layout from the given point from the reference
+ * output. If reference is Nil, gets the closest point from the entire layout.
+ * If the layout is empty, the result is the given point itself.
+ */
+func (l OutputLayout) ClosestPoint(reference Output, lx float64, ly float64) (x float64, y float64) {
+	debugCheck(unsafe.Pointer(l.p))
+	var cx, cy C.double
+	C.wlr_output_layout_closest_point(l.p, reference.p, C.double(lx), C.double(ly), &cx, &cy)
+	return float64(cx), float64(cy)
+}
+
+/**
+ * Get the box of the layout for the given reference output in layout
+ * coordinates. If reference is Nil, the box will be for the extents of the
+ * entire layout. If the output isn't in the layout, the box will be empty.
+ */
+func (l OutputLayout) GetBox(reference Output) GeoBox {
+	debugCheck(unsafe.Pointer(l.p))
+	var cb C.struct_wlr_box
+	C.wlr_output_layout_get_box(l.p, reference.p, &cb)
+
+	var b GeoBox
+	b.fromC(&cb)
+	return b
+}
+
+/**
+ * Get the output closest to the center of the layout extents.
+ */
+func (l OutputLayout) CenterOutput() Output {
+	debugCheck(unsafe.Pointer(l.p))
+	return Output{p: C.wlr_output_layout_get_center_output(l.p)}
+}
+
+/**
+ * Get the closest adjacent handle to the reference output from the reference
+ * point in the given direction. Nil if there is none.
+ */
+func (l OutputLayout) AdjacentOutput(direction Direction, reference Output, refLX float64, refLY float64) Output {
+	debugCheck(unsafe.Pointer(l.p))
+	p := C.wlr_output_layout_adjacent_output(l.p, C.enum_wlr_direction(direction), reference.p,
+		C.double(refLX), C.double(refLY))
+	return Output{p: p}
+}
+
+/**
+ * Get the output farthest from the reference point in the given direction,
+ * ignoring the reference output itself. Nil if there is none.
+ */
+func (l OutputLayout) FarthestOutput(direction Direction, reference Output, refLX float64, refLY float64) Output {
+	debugCheck(unsafe.Pointer(l.p))
+	p := C.wlr_output_layout_farthest_output(l.p, C.enum_wlr_direction(direction), reference.p,
+		C.double(refLX), C.double(refLY))
+	return Output{p: p}
+}
+
+func (lo OutputLayoutOutput) Nil() bool {
+	return lo.p == nil
+}
+
+func (lo OutputLayoutOutput) Output() Output {
+	debugCheck(unsafe.Pointer(lo.p))
+	return Output{p: lo.p.output}
+}
+
+// X and Y are the position of the output in layout coordinates.
+func (lo OutputLayoutOutput) X() int {
+	debugCheck(unsafe.Pointer(lo.p))
+	return int(lo.p.x)
+}
+
+func (lo OutputLayoutOutput) Y() int {
+	debugCheck(unsafe.Pointer(lo.p))
+	return int(lo.p.y)
+}
+
+// AutoConfigured reports whether the position is picked by the layout, as
+// opposed to being set with Add.
+func (lo OutputLayoutOutput) AutoConfigured() bool {
+	debugCheck(unsafe.Pointer(lo.p))
+	return bool(lo.p.auto_configured)
+}
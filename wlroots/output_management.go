@@ -0,0 +1,252 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/types/wlr_output.h>
+// #include <wlr/types/wlr_output_management_v1.h>
+import "C"
+
+/**
+ * Implementation of the wlr-output-management-unstable-v1 protocol, used by
+ * tools such as kanshi and wlr-randr to list and configure outputs.
+ *
+ * The compositor publishes the current state with SetConfiguration whenever
+ * it changes. Clients request changes, which arrive through OnTest and
+ * OnApply.
+ */
+type OutputManagerV1 struct {
+	p *C.struct_wlr_output_manager_v1
+}
+
+// OutputCustomMode is a mode that isn't in the output's list of modes.
+// Refresh is in mHz and may be zero.
+type OutputCustomMode struct {
+	Width   int
+	Height  int
+	Refresh int
+}
+
+/**
+ * The state of a single output, either current or requested by a client.
+ * Mode is Nil when a custom mode is used.
+ */
+type OutputHeadState struct {
+	Output              Output
+	Enabled             bool
+	Mode                OutputMode
+	CustomMode          OutputCustomMode
+	X                   int
+	Y                   int
+	Transform           OutputTransform
+	Scale               float32
+	AdaptiveSyncEnabled bool
+}
+
+func (s *OutputHeadState) fromC(cs *C.struct_wlr_output_head_v1_state) {
+	s.Output = Output{p: cs.output}
+	s.Enabled = bool(cs.enabled)
+	s.Mode = OutputMode{p: cs.mode}
+	s.CustomMode = OutputCustomMode{
+		Width:   int(cs.custom_mode.width),
+		Height:  int(cs.custom_mode.height),
+		Refresh: int(cs.custom_mode.refresh),
+	}
+	s.X = int(cs.x)
+	s.Y = int(cs.y)
+	s.Transform = OutputTransform(cs.transform)
+	s.Scale = float32(cs.scale)
+	s.AdaptiveSyncEnabled = bool(cs.adaptive_sync_enabled)
+}
+
+func (s *OutputHeadState) toC(cs *C.struct_wlr_output_head_v1_state) {
+	cs.output = s.Output.p
+	cs.enabled = C.bool(s.Enabled)
+	cs.mode = s.Mode.p
+	cs.custom_mode.width = C.int(s.CustomMode.Width)
+	cs.custom_mode.height = C.int(s.CustomMode.Height)
+	cs.custom_mode.refresh = C.int(s.CustomMode.Refresh)
+	cs.x = C.int32_t(s.X)
+	cs.y = C.int32_t(s.Y)
+	cs.transform = C.enum_wl_output_transform(s.Transform)
+	cs.scale = C.float(s.Scale)
+	cs.adaptive_sync_enabled = C.bool(s.AdaptiveSyncEnabled)
+}
+
+/**
+ * Apply the head state on the supplied struct wlr_output_state.
+ *
+ * Compositors can then pass the resulting struct wlr_output_state to
+ * wlr_output_commit_state() or wlr_output_test_state().
+ *
+ * The position is not part of the output state; it has to be applied to the
+ * output layout separately.
+ */
+func (s OutputHeadState) Apply(state OutputState) {
+	debugCheck(unsafe.Pointer(s.Output.p))
+	var cs C.struct_wlr_output_head_v1_state
+	s.toC(&cs)
+	C.wlr_output_head_v1_state_apply(&cs, state.p)
+}
+
+/**
+ * A configuration requested by a client. It must be answered with Succeeded or
+ * Failed, which also releases it. When several callbacks receive the same
+ * configuration, only the first reply counts and later ones are ignored.
+ */
+type OutputConfiguration struct {
+	p *C.struct_wlr_output_configuration_v1
+
+	// Heads lists the requested state of every output. Outputs missing from
+	// the list are to be left alone.
+	Heads []OutputHeadState
+}
+
+// repliedConfigs holds the configurations that have been answered, and freed,
+// already. NewOutputManagerV1 clears an entry when a new configuration shows up
+// at the same address.
+var (
+	repliedConfigs      = map[*C.struct_wlr_output_configuration_v1]struct{}{}
+	repliedConfigsMutex sync.Mutex
+)
+
+func wrapOutputConfiguration(p *C.struct_wlr_output_configuration_v1) OutputConfiguration {
+	c := OutputConfiguration{p: p}
+
+	var head *C.struct_wlr_output_configuration_head_v1
+	offset := unsafe.Offsetof(head.link)
+	for link := p.heads.next; link != &p.heads; link = link.next {
+		head = (*C.struct_wlr_output_configuration_head_v1)(unsafe.Add(unsafe.Pointer(link), -int(offset)))
+		var s OutputHeadState
+		s.fromC(&head.state)
+		c.Heads = append(c.Heads, s)
+	}
+	return c
+}
+
+/**
+ * If the configuration comes from a client request, this sends positive
+ * feedback to the client (configuration has been applied).
+ */
+func (c OutputConfiguration) Succeeded() {
+	debugCheck(unsafe.Pointer(c.p))
+	if !c.markReplied() {
+		return
+	}
+	C.wlr_output_configuration_v1_send_succeeded(c.p)
+	C.wlr_output_configuration_v1_destroy(c.p)
+}
+
+/**
+ * If the configuration comes from a client request, this sends negative
+ * feedback to the client (configuration has not been applied).
+ */
+func (c OutputConfiguration) Failed() {
+	debugCheck(unsafe.Pointer(c.p))
+	if !c.markReplied() {
+		return
+	}
+	C.wlr_output_configuration_v1_send_failed(c.p)
+	C.wlr_output_configuration_v1_destroy(c.p)
+}
+
+// markReplied records that the configuration has been answered. It returns
+// false if it was answered before.
+func (c OutputConfiguration) markReplied() bool {
+	repliedConfigsMutex.Lock()
+	defer repliedConfigsMutex.Unlock()
+	if _, found := repliedConfigs[c.p]; found {
+		return false
+	}
+	repliedConfigs[c.p] = struct{}{}
+	return true
+}
+
+func NewOutputManagerV1(display Display) OutputManagerV1 {
+	debugCheck(unsafe.Pointer(display.p))
+	p := C.wlr_output_manager_v1_create(display.p)
+	man.track(p, &p.events.destroy)
+
+	// added before any OnApply or OnTest callback, so these run first
+	for _, signal := range []*C.struct_wl_signal{&p.events.apply, &p.events.test} {
+		man.add(p, signal, func(data unsafe.Pointer) {
+			repliedConfigsMutex.Lock()
+			delete(repliedConfigs, (*C.struct_wlr_output_configuration_v1)(data))
+			repliedConfigsMutex.Unlock()
+		})
+	}
+	return OutputManagerV1{p: p}
+}
+
+func (m OutputManagerV1) OnDestroy(cb func(OutputManagerV1)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
+		cb(m)
+	})
+}
+
+/**
+ * Emitted when a client requests to apply a new configuration. The
+ * compositor should try to apply it and reply with Succeeded or Failed. On
+ * success, the new state should be published again with SetConfiguration.
+ */
+func (m OutputManagerV1) OnApply(cb func(OutputManagerV1, OutputConfiguration)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.apply, func(data unsafe.Pointer) {
+		cb(m, wrapOutputConfiguration((*C.struct_wlr_output_configuration_v1)(data)))
+	})
+}
+
+/**
+ * Emitted when a client wants to know whether a configuration would be
+ * accepted, without applying it. Reply with Succeeded or Failed.
+ */
+func (m OutputManagerV1) OnTest(cb func(OutputManagerV1, OutputConfiguration)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.test, func(data unsafe.Pointer) {
+		cb(m, wrapOutputConfiguration((*C.struct_wlr_output_configuration_v1)(data)))
+	})
+}
+
+/**
+ * Updates the output manager's current configuration. This will broadcast any
+ * changes to all clients.
+ *
+ * Every output of the compositor should be listed, including disabled ones.
+ * Outputs are reported as enabled if they are enabled and part of the layout,
+ * at their position in the layout.
+ */
+func (m OutputManagerV1) SetConfiguration(layout OutputLayout, outputs []Output) {
+	debugCheck(unsafe.Pointer(m.p))
+	config := C.wlr_output_configuration_v1_create()
+	if config == nil {
+		return
+	}
+
+	for _, output := range outputs {
+		head := C.wlr_output_configuration_head_v1_create(config, output.p)
+		if head == nil {
+			C.wlr_output_configuration_v1_destroy(config)
+			return
+		}
+
+		lo := layout.Get(output)
+		head.state.enabled = head.state.enabled && C.bool(!lo.Nil())
+		if !lo.Nil() {
+			head.state.x = C.int32_t(lo.X())
+			head.state.y = C.int32_t(lo.Y())
+		}
+	}
+
+	// takes ownership of config
+	C.wlr_output_manager_v1_set_configuration(m.p, config)
+}
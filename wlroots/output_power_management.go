@@ -0,0 +1,57 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/types/wlr_output_power_management_v1.h>
+import "C"
+
+type OutputPowerMode uint32
+
+const (
+	OutputPowerModeOff OutputPowerMode = C.ZWLR_OUTPUT_POWER_V1_MODE_OFF
+	OutputPowerModeOn  OutputPowerMode = C.ZWLR_OUTPUT_POWER_V1_MODE_ON
+)
+
+/**
+ * Implementation of the wlr-output-power-management-unstable-v1 protocol,
+ * which lets idle daemons such as swayidle turn outputs off and on again.
+ */
+type OutputPowerManagerV1 struct {
+	p *C.struct_wlr_output_power_manager_v1
+}
+
+func NewOutputPowerManagerV1(display Display) OutputPowerManagerV1 {
+	debugCheck(unsafe.Pointer(display.p))
+	p := C.wlr_output_power_manager_v1_create(display.p)
+	man.track(p, &p.events.destroy)
+	return OutputPowerManagerV1{p: p}
+}
+
+func (m OutputPowerManagerV1) OnDestroy(cb func(OutputPowerManagerV1)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
+		cb(m)
+	})
+}
+
+/**
+ * Emitted when a client requests a new power mode for an output. The
+ * compositor is expected to commit an OutputState enabling or disabling the
+ * output accordingly.
+ */
+func (m OutputPowerManagerV1) OnSetMode(cb func(OutputPowerManagerV1, Output, OutputPowerMode)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.set_mode, func(data unsafe.Pointer) {
+		event := (*C.struct_wlr_output_power_v1_set_mode_event)(data)
+		cb(m, Output{p: event.output}, OutputPowerMode(event.mode))
+	})
+}
@@ -0,0 +1,147 @@
+package wlroots
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unsafe"
+)
+
+// PanicPolicy determines what happens when a callback registered with one of
+// the On* methods, a global filter or a capture policy panics.
+type PanicPolicy uint32
+
+const (
+	// PanicPolicyRepanic reports the panic to the OnCallbackError hook and
+	// then panics again with a *CallbackError, which crashes the process.
+	// This is the default.
+	PanicPolicyRepanic PanicPolicy = iota
+	// PanicPolicyRecover reports the panic to the OnCallbackError hook, or
+	// logs it if no hook is set, and carries on with the next callback.
+	PanicPolicyRecover
+)
+
+// CallbackError describes a panic inside a callback.
+type CallbackError struct {
+	// Callback is the method the callback was registered with, e.g.
+	// "Output.OnFrame".
+	Callback string
+	// Object is the address of the wlroots object the callback was
+	// registered on.
+	Object uintptr
+	// Signal is the address of the wl_signal that was emitted, or zero for
+	// callbacks that are not attached to a signal.
+	Signal uintptr
+	// Value is the value the callback panicked with.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *CallbackError) Error() string {
+	return fmt.Sprintf("panic in %s callback (object %#x, signal %#x): %v", e.Callback, e.Object, e.Signal, e.Value)
+}
+
+// Unwrap returns the value the callback panicked with if it is an error.
+func (e *CallbackError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+var (
+	panicPolicy     atomic.Uint32
+	onCallbackError atomic.Pointer[func(*CallbackError)]
+)
+
+// SetPanicPolicy sets the policy for panics inside callbacks.
+func SetPanicPolicy(policy PanicPolicy) {
+	panicPolicy.Store(uint32(policy))
+}
+
+// OnCallbackError sets a hook that is called whenever a callback panics,
+// regardless of the panic policy. Passing nil removes the hook.
+func OnCallbackError(cb func(*CallbackError)) {
+	if cb == nil {
+		onCallbackError.Store(nil)
+	} else {
+		onCallbackError.Store(&cb)
+	}
+}
+
+func runCallback(l *listener, cb *callback, data unsafe.Pointer) {
+	guard(cb.name, uintptr(l.obj), uintptr(unsafe.Pointer(l.s)), func() {
+		cb.fn(data)
+	})
+}
+
+// guard runs fn, a Go callback invoked from C, under the panic policy. If fn
+// panics and the policy recovers from it, guard returns the error describing
+// the panic, so that callers can fall back to a safe default or pass it on.
+func guard(name string, object uintptr, signal uintptr, fn func()) (err *CallbackError) {
+	ok := false
+	defer func() {
+		if ok {
+			return
+		}
+		v := recover()
+
+		err = &CallbackError{
+			Callback: name,
+			Object:   object,
+			Signal:   signal,
+			Value:    v,
+			Stack:    debug.Stack(),
+		}
+		hook := onCallbackError.Load()
+		if hook != nil {
+			(*hook)(err)
+		}
+
+		if PanicPolicy(panicPolicy.Load()) == PanicPolicyRecover {
+			if hook == nil {
+				slog.Error("recovered from panic in callback", "err", err, "stack", string(err.Stack))
+			}
+			return
+		}
+		panic(err)
+	}()
+
+	fn()
+	ok = true
+	return nil
+}
+
+// callerName returns the name of the exported function or method of this
+// package that is registering a callback, such as "Output.OnFrame".
+func callerName() string {
+	const pkg = "github.com/swaywm/go-wlroots/wlroots."
+
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		name, found := strings.CutPrefix(frame.Function, pkg)
+		if !found {
+			break
+		}
+
+		// strip closures, e.g. "XDGShell.OnNewSurface.func1"
+		parts := strings.Split(name, ".")
+		for len(parts) > 1 && strings.HasPrefix(parts[len(parts)-1], "func") {
+			parts = parts[:len(parts)-1]
+		}
+		if last := parts[len(parts)-1]; unicode.IsUpper([]rune(last)[0]) {
+			return strings.Join(parts, ".")
+		}
+
+		if !more {
+			break
+		}
+	}
+	return "unknown"
+}
@@ -5,27 +5,123 @@ package wlroots
  * future consistency of this API.
  */
 
-import "unsafe"
+import (
+	"errors"
+	"unsafe"
+)
 
 // #cgo pkg-config: wlroots-0.18 wayland-server
 // #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/config.h>
+// #include <wlr/render/pixman.h>
 // #include <wlr/render/wlr_renderer.h>
+// #if WLR_HAS_GLES2_RENDERER
+// #include <wlr/render/gles2.h>
+// #endif
+// #if WLR_HAS_VULKAN_RENDERER
+// #include <wlr/render/vulkan.h>
+// #endif
+//
+// static inline struct wlr_renderer *_wlr_gles2_renderer_create_with_drm_fd(int drm_fd) {
+// #if WLR_HAS_GLES2_RENDERER
+//		return wlr_gles2_renderer_create_with_drm_fd(drm_fd);
+// #else
+//		return NULL;
+// #endif
+// }
+//
+// static inline bool _wlr_renderer_is_gles2(struct wlr_renderer *r) {
+// #if WLR_HAS_GLES2_RENDERER
+//		return wlr_renderer_is_gles2(r);
+// #else
+//		return false;
+// #endif
+// }
+//
+// static inline bool _wlr_renderer_is_vk(struct wlr_renderer *r) {
+// #if WLR_HAS_VULKAN_RENDERER
+//		return wlr_renderer_is_vk(r);
+// #else
+//		return false;
+// #endif
+// }
 import "C"
 
 type Renderer struct {
 	p *C.struct_wlr_renderer
 }
 
+/**
+ * Creates a software renderer, which works without a GPU.
+ */
+func NewPixmanRenderer() (Renderer, error) {
+	p := C.wlr_pixman_renderer_create()
+	if p == nil {
+		return Renderer{}, errors.New("failed to create pixman renderer")
+	}
+	man.track(p, &p.events.destroy)
+	return Renderer{p: p}, nil
+}
+
+/**
+ * Creates an OpenGL ES 2 renderer on the DRM device behind drmFD, e.g. the
+ * one returned by Backend.DRMFD. The file descriptor is not consumed.
+ *
+ * Fails if wlroots was built without the GLES2 renderer.
+ */
+func NewGLES2RendererWithDRMFD(drmFD int) (Renderer, error) {
+	p := C._wlr_gles2_renderer_create_with_drm_fd(C.int(drmFD))
+	if p == nil {
+		return Renderer{}, errors.New("failed to create gles2 renderer")
+	}
+	man.track(p, &p.events.destroy)
+	return Renderer{p: p}, nil
+}
+
+func (r Renderer) IsPixman() bool {
+	debugCheck(unsafe.Pointer(r.p))
+	return bool(C.wlr_renderer_is_pixman(r.p))
+}
+
+// IsGLES2 always reports false if wlroots was built without GLES2 support.
+func (r Renderer) IsGLES2() bool {
+	debugCheck(unsafe.Pointer(r.p))
+	return bool(C._wlr_renderer_is_gles2(r.p))
+}
+
+// IsVulkan always reports false if wlroots was built without Vulkan support.
+func (r Renderer) IsVulkan() bool {
+	debugCheck(unsafe.Pointer(r.p))
+	return bool(C._wlr_renderer_is_vk(r.p))
+}
+
 func (r Renderer) Destroy() {
+	debugCheck(unsafe.Pointer(r.p))
 	C.wlr_renderer_destroy(r.p)
 }
 
-func (r Renderer) OnDestroy(cb func(Renderer)) {
-	man.add(unsafe.Pointer(r.p), &r.p.events.destroy, func(unsafe.Pointer) {
+func (r Renderer) OnDestroy(cb func(Renderer)) Listener {
+	debugCheck(unsafe.Pointer(r.p))
+	return man.add(r.p, &r.p.events.destroy, func(unsafe.Pointer) {
+		cb(r)
+	})
+}
+
+/**
+ * Emitted when the GPU is lost, e.g. on GPU reset.
+ *
+ * Compositors should destroy the renderer and re-create it, together with
+ * the allocator and anything else that depends on it, such as the scene
+ * outputs' render state.
+ */
+func (r Renderer) OnLost(cb func(Renderer)) Listener {
+	debugCheck(unsafe.Pointer(r.p))
+	return man.add(r.p, &r.p.events.lost, func(unsafe.Pointer) {
 		cb(r)
 	})
 }
 
 func (r Renderer) InitDisplay(display Display) {
+	debugCheck(unsafe.Pointer(r.p))
 	C.wlr_renderer_init_wl_display(r.p, display.p)
 }
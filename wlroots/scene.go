@@ -48,9 +48,17 @@ func (s Scene) NewOutput(o Output) SceneOutput {
 
 func NewScene() Scene {
 	p := C.wlr_scene_create()
+	debugAlive(unsafe.Pointer(p))
 	return Scene{p: p}
 }
 
+/**
+ * Destroy the scene-graph along with all of its nodes.
+ */
+func (s Scene) Destroy() {
+	C.wlr_scene_node_destroy(&s.p.tree.node)
+}
+
 func (s Scene) AttachOutputLayout(layout OutputLayout) SceneOutputLayout {
 	p := C.wlr_scene_attach_output_layout(s.p, layout.p)
 	return SceneOutputLayout{p: p}
@@ -95,6 +103,7 @@ func (st SceneTree) Nil() bool {
  */
 func (parent SceneTree) NewSceneTree() SceneTree {
 	p := C.wlr_scene_tree_create(parent.p)
+	debugAlive(unsafe.Pointer(p))
 	return SceneTree{p: p}
 }
 
@@ -110,6 +119,10 @@ func (parent SceneTree) NewSceneTree() SceneTree {
 
 func (parent SceneTree) NewSurface(surface Surface) SceneSurface {
 	p := C.wlr_scene_surface_create(parent.p, surface.p)
+	if p != nil {
+		// the node belongs to the scene buffer displaying the surface
+		debugAlive(unsafe.Pointer(p.buffer))
+	}
 	return SceneSurface{p: p}
 }
 
@@ -126,6 +139,7 @@ func (st SceneTree) Node() SceneNode {
  */
 func (st SceneTree) XDGSurfaceCreate(s XDGSurface) SceneTree {
 	p := C.wlr_scene_xdg_surface_create(st.p, s.p)
+	debugAlive(unsafe.Pointer(p))
 	return SceneTree{p: p}
 }
 func (st SceneTree) NewXDGSurface(s XDGSurface) SceneTree {
@@ -134,6 +148,8 @@ func (st SceneTree) NewXDGSurface(s XDGSurface) SceneTree {
 
 func (parent SceneTree) BufferCreate(b Buffer) SceneBuffer {
 	p := C.wlr_scene_buffer_create(parent.p, b.p)
+	debugAlive(unsafe.Pointer(p))
+	setSceneBuffer(p, nil, b.p)
 	return SceneBuffer{p: p}
 }
 func (parent SceneTree) NewBuffer(b Buffer) SceneBuffer {
@@ -168,7 +184,9 @@ func (sb SceneBuffer) SendFrameDone(when time.Time) {
 }
 
 func (sb SceneBuffer) SetBuffer(b Buffer) {
+	old := sb.p.buffer
 	C.wlr_scene_buffer_set_buffer(sb.p, b.p)
+	setSceneBuffer(sb.p, old, b.p)
 }
 
 /** A viewport for an output in the scene-graph */
@@ -219,6 +237,7 @@ type SceneNode struct {
  * is not legal to feed a node that does not represent a wlr_scene_tree.
  */
 func (sn SceneNode) SceneTree() SceneTree {
+	debugCheck(unsafe.Pointer(sn.p))
 	p := C.wlr_scene_tree_from_node(sn.p)
 	return SceneTree{p: p}
 }
@@ -228,6 +247,7 @@ func (sn SceneNode) SceneTree() SceneTree {
  * is not legal to feed a node that does not represent a wlr_scene_rect.
  */
 func (sn SceneNode) SceneRect() SceneRect {
+	debugCheck(unsafe.Pointer(sn.p))
 	p := C.wlr_scene_rect_from_node(sn.p)
 	return SceneRect{p: p}
 }
@@ -237,6 +257,7 @@ func (sn SceneNode) SceneRect() SceneRect {
  * is not legal to feed a node that does not represent a wlr_scene_buffer.
  */
 func (sn SceneNode) SceneBuffer() SceneBuffer {
+	debugCheck(unsafe.Pointer(sn.p))
 	p := C.wlr_scene_buffer_from_node(sn.p)
 	return SceneBuffer{p: p}
 }
@@ -245,6 +266,7 @@ func (sn SceneNode) SceneBuffer() SceneBuffer {
  * Immediately destroy the scene-graph node.
  */
 func (sn SceneNode) Destroy() {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_destroy(sn.p)
 }
 
@@ -252,6 +274,7 @@ func (sn SceneNode) Destroy() {
  * Move the node below all of its sibling nodes.
  */
 func (sn SceneNode) LowerToBottom() {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_lower_to_bottom(sn.p)
 }
 
@@ -260,6 +283,7 @@ func (sn SceneNode) LowerToBottom() {
  * Asserts that node and sibling are distinct and share the same parent.
  */
 func (sn SceneNode) PlaceAbove(sib SceneNode) {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_place_above(sn.p, sib.p)
 }
 
@@ -268,6 +292,7 @@ func (sn SceneNode) PlaceAbove(sib SceneNode) {
  * Asserts that node and sibling are distinct and share the same parent.
  */
 func (sn SceneNode) PlaceBellow(sib SceneNode) {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_place_below(sn.p, sib.p)
 }
 
@@ -275,6 +300,7 @@ func (sn SceneNode) PlaceBellow(sib SceneNode) {
  * Move the node above all of its sibling nodes.
  */
 func (sn SceneNode) RaiseToTop() {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_raise_to_top(sn.p)
 }
 
@@ -282,6 +308,7 @@ func (sn SceneNode) RaiseToTop() {
  * Move the node to another location in the tree.
  */
 func (sn SceneNode) Reparent(tree SceneTree) {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_reparent(sn.p, tree.p)
 }
 
@@ -290,6 +317,7 @@ func (sn SceneNode) Reparent(tree SceneTree) {
  * implicitly disabled as well.
  */
 func (sn SceneNode) SetEnabled(enabled bool) {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_set_enabled(sn.p, C.bool(enabled))
 }
 
@@ -297,6 +325,7 @@ func (sn SceneNode) SetEnabled(enabled bool) {
  * Set the position of the node relative to its parent.
  */
 func (sn SceneNode) SetPosition(x float64, y float64) {
+	debugCheck(unsafe.Pointer(sn.p))
 	C.wlr_scene_node_set_position(sn.p, C.int(x), C.int(y))
 }
 
@@ -307,6 +336,7 @@ func (sn SceneNode) SetPosition(x float64, y float64) {
  * returned node, or NULL if no node is found at that location.
  */
 func (sn SceneNode) At(x float64, y float64) (SceneNode, float64, float64) {
+	debugCheck(unsafe.Pointer(sn.p))
 	var lx *C.double = new(C.double)
 	var ly *C.double = new(C.double)
 	p := C.wlr_scene_node_at(sn.p, C.double(x), C.double(y), lx, ly)
@@ -318,28 +348,47 @@ func (sn SceneNode) Nil() bool {
 }
 
 func (sn SceneNode) Type() SceneNodeType {
+	debugCheck(unsafe.Pointer(sn.p))
 	return SceneNodeType(sn.p._type)
 }
 
 func (sn SceneNode) Parent() SceneTree {
+	debugCheck(unsafe.Pointer(sn.p))
 	return SceneTree{p: sn.p.parent}
 }
 
 // relative to parent
 func (sn SceneNode) X() int {
+	debugCheck(unsafe.Pointer(sn.p))
 	return int(sn.p.x)
 }
 
 // relative to parent
 func (sn SceneNode) Y() int {
+	debugCheck(unsafe.Pointer(sn.p))
 	return int(sn.p.y)
 }
 
 func (sn SceneNode) SetData(tree SceneTree) {
+	debugCheck(unsafe.Pointer(sn.p))
 	sn.p.data = unsafe.Pointer(tree.p)
 }
 
+// SetUserData attaches an arbitrary Go value to the node. Unlike SetData it
+// accepts any type, and the value is released when the node is destroyed.
+func (sn SceneNode) SetUserData(v any) {
+	debugCheck(unsafe.Pointer(sn.p))
+	man.setUserData(sn.p, &sn.p.events.destroy, v)
+}
+
+// UserData returns the value set with SetUserData, or nil.
+func (sn SceneNode) UserData() any {
+	debugCheck(unsafe.Pointer(sn.p))
+	return man.getUserData(unsafe.Pointer(sn.p))
+}
+
 func (x SceneNode) SceneTreeFromData() SceneTree {
+	debugCheck(unsafe.Pointer(x.p))
 	// slog.Debug("XDGSurface SceneTree(): x.p", x.p)
 	// slog.Debug("XDGSurface SceneTree(): x.p.data", x.p.data)
 	return SceneTree{p: (*C.struct_wlr_scene_tree)(x.p.data)}
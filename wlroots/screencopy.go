@@ -0,0 +1,93 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <wlr/types/wlr_export_dmabuf_v1.h>
+// #include <wlr/types/wlr_screencopy_v1.h>
+import "C"
+
+/**
+ * Implementation of the wlr-screencopy-unstable-v1 protocol, used by
+ * screenshot tools such as grim and by screen recorders.
+ */
+type ScreencopyManagerV1 struct {
+	p       *C.struct_wlr_screencopy_manager_v1
+	display *C.struct_wl_display
+}
+
+/**
+ * Implementation of the wlr-export-dmabuf-unstable-v1 protocol, which lets
+ * recorders such as wf-recorder grab output contents without copies.
+ */
+type ExportDmabufManagerV1 struct {
+	p       *C.struct_wlr_export_dmabuf_manager_v1
+	display *C.struct_wl_display
+}
+
+func (d Display) NewScreencopyManagerV1() ScreencopyManagerV1 {
+	debugCheck(unsafe.Pointer(d.p))
+	p := C.wlr_screencopy_manager_v1_create(d.p)
+	man.track(p, &p.events.destroy)
+	global := p.global
+	man.addLast(p, &p.events.destroy, func(unsafe.Pointer) {
+		setGlobalPolicy(d.p, global, nil, "")
+	})
+	return ScreencopyManagerV1{p: p, display: d.p}
+}
+
+func (m ScreencopyManagerV1) OnDestroy(cb func(ScreencopyManagerV1)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
+		cb(m)
+	})
+}
+
+/**
+ * Restricts screencopy to the clients approved by policy. Other clients don't
+ * see the global at all, so tools report that screencopy is unsupported
+ * rather than capturing a blank image. A nil policy allows every client,
+ * which is the default.
+ *
+ * The policy is checked whenever the global is advertised to or bound by a
+ * client, and it should be fast.
+ */
+func (m ScreencopyManagerV1) SetCapturePolicy(policy CapturePolicyFunc) {
+	debugCheck(unsafe.Pointer(m.p))
+	setGlobalPolicy(m.display, m.p.global, policy, "ScreencopyManagerV1.SetCapturePolicy")
+}
+
+func (d Display) NewExportDmabufManagerV1() ExportDmabufManagerV1 {
+	debugCheck(unsafe.Pointer(d.p))
+	p := C.wlr_export_dmabuf_manager_v1_create(d.p)
+	man.track(p, &p.events.destroy)
+	global := p.global
+	man.addLast(p, &p.events.destroy, func(unsafe.Pointer) {
+		setGlobalPolicy(d.p, global, nil, "")
+	})
+	return ExportDmabufManagerV1{p: p, display: d.p}
+}
+
+func (m ExportDmabufManagerV1) OnDestroy(cb func(ExportDmabufManagerV1)) Listener {
+	debugCheck(unsafe.Pointer(m.p))
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
+		cb(m)
+	})
+}
+
+/**
+ * Like ScreencopyManagerV1.SetCapturePolicy, restricts DMA-BUF export to the
+ * clients approved by policy. A nil policy allows every client.
+ */
+func (m ExportDmabufManagerV1) SetCapturePolicy(policy CapturePolicyFunc) {
+	debugCheck(unsafe.Pointer(m.p))
+	setGlobalPolicy(m.display, m.p.global, policy, "ExportDmabufManagerV1.SetCapturePolicy")
+}
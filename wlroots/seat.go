@@ -20,6 +20,10 @@ type SeatClient struct {
 	p *C.struct_wlr_seat_client
 }
 
+func (c SeatClient) Client() Client {
+	return Client{p: c.p.client}
+}
+
 type SeatKeyboardState struct {
 	s C.struct_wlr_seat_keyboard_state
 }
@@ -37,17 +41,20 @@ const (
 )
 
 func (s Seat) Destroy() {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_destroy(s.p)
 }
 
-func (s Seat) OnDestroy(cb func(Seat)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.destroy, func(unsafe.Pointer) {
+func (s Seat) OnDestroy(cb func(Seat)) Listener {
+	debugCheck(unsafe.Pointer(s.p))
+	return man.add(s.p, &s.p.events.destroy, func(unsafe.Pointer) {
 		cb(s)
 	})
 }
 
-func (s Seat) OnSetCursorRequest(cb func(client SeatClient, surface Surface, serial uint32, hotspotX int32, hotspotY int32)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.request_set_cursor, func(data unsafe.Pointer) {
+func (s Seat) OnSetCursorRequest(cb func(client SeatClient, surface Surface, serial uint32, hotspotX int32, hotspotY int32)) Listener {
+	debugCheck(unsafe.Pointer(s.p))
+	return man.add(s.p, &s.p.events.request_set_cursor, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_seat_pointer_request_set_cursor_event)(data)
 		client := SeatClient{p: event.seat_client}
 		surface := Surface{p: event.surface}
@@ -56,59 +63,73 @@ func (s Seat) OnSetCursorRequest(cb func(client SeatClient, surface Surface, ser
 }
 
 func (s Seat) SetCapabilities(caps SeatCapability) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_set_capabilities(s.p, C.uint32_t(caps))
 }
 
 func (s Seat) SetKeyboard(dev InputDevice) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_set_keyboard(s.p, dev.Keyboard().p)
 }
 
 func (s Seat) NotifyPointerButton(time uint32, button uint32, state ButtonState) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_pointer_notify_button(s.p, C.uint32_t(time), C.uint32_t(button), uint32(state))
 }
 
 func (s Seat) NotifyPointerAxis(time uint32, orientation AxisOrientation, delta float64, deltaDiscrete int32, source AxisSource, relativeDirection RelativeDirection) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_pointer_notify_axis(s.p, C.uint32_t(time), C.enum_wl_pointer_axis(orientation), C.double(delta), C.int32_t(deltaDiscrete), C.enum_wl_pointer_axis_source(source), C.enum_wl_pointer_axis_relative_direction(relativeDirection))
 }
 
 func (s Seat) NotifyPointerEnter(surface Surface, sx float64, sy float64) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_pointer_notify_enter(s.p, surface.p, C.double(sx), C.double(sy))
 }
 
 func (s Seat) NotifyPointerMotion(time uint32, sx float64, sy float64) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_pointer_notify_motion(s.p, C.uint32_t(time), C.double(sx), C.double(sy))
 }
 
 func (s Seat) NotifyPointerFrame() {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_pointer_notify_frame(s.p)
 }
 
 func (s Seat) NotifyKeyboardEnter(surface Surface, k Keyboard) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_keyboard_notify_enter(s.p, surface.p, &k.p.keycodes[0], k.p.num_keycodes, &k.p.modifiers)
 }
 
 func (s Seat) NotifyKeyboardModifiers(k Keyboard) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_keyboard_notify_modifiers(s.p, &k.p.modifiers)
 }
 
 func (s Seat) NotifyKeyboardKey(time uint32, keyCode uint32, state KeyState) {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_keyboard_notify_key(s.p, C.uint32_t(time), C.uint32_t(keyCode), C.uint32_t(state))
 }
 
 func (s Seat) ClearPointerFocus() {
+	debugCheck(unsafe.Pointer(s.p))
 	C.wlr_seat_pointer_clear_focus(s.p)
 }
 
 func (s Seat) Keyboard() Keyboard {
+	debugCheck(unsafe.Pointer(s.p))
 	p := C.wlr_seat_get_keyboard(s.p)
 	return Keyboard{p: p}
 }
 
 func (s Seat) KeyboardState() SeatKeyboardState {
+	debugCheck(unsafe.Pointer(s.p))
 	return SeatKeyboardState{s: s.p.keyboard_state}
 }
 
 func (s Seat) PointerState() SeatPointerState {
+	debugCheck(unsafe.Pointer(s.p))
 	return SeatPointerState{s: s.p.pointer_state}
 }
 
@@ -32,12 +32,12 @@ type ServerDecoration struct {
 
 func NewServerDecorationManager(display Display) ServerDecorationManager {
 	p := C.wlr_server_decoration_manager_create(display.p)
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return ServerDecorationManager{p: p}
 }
 
-func (m ServerDecorationManager) OnDestroy(cb func(ServerDecorationManager)) {
-	man.add(unsafe.Pointer(m.p), &m.p.events.destroy, func(unsafe.Pointer) {
+func (m ServerDecorationManager) OnDestroy(cb func(ServerDecorationManager)) Listener {
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
 		cb(m)
 	})
 }
@@ -46,24 +46,24 @@ func (m ServerDecorationManager) SetDefaultMode(mode ServerDecorationManagerMode
 	C.wlr_server_decoration_manager_set_default_mode(m.p, C.uint32_t(mode))
 }
 
-func (m ServerDecorationManager) OnNewMode(cb func(ServerDecorationManager, ServerDecoration)) {
-	man.add(unsafe.Pointer(m.p), &m.p.events.new_decoration, func(data unsafe.Pointer) {
+func (m ServerDecorationManager) OnNewMode(cb func(ServerDecorationManager, ServerDecoration)) Listener {
+	return man.add(m.p, &m.p.events.new_decoration, func(data unsafe.Pointer) {
 		dec := ServerDecoration{
 			p: (*C.struct_wlr_server_decoration)(data),
 		}
-		man.track(unsafe.Pointer(dec.p), &dec.p.events.destroy)
+		man.track(dec.p, &dec.p.events.destroy)
 		cb(m, dec)
 	})
 }
 
-func (d ServerDecoration) OnDestroy(cb func(ServerDecoration)) {
-	man.add(unsafe.Pointer(d.p), &d.p.events.destroy, func(unsafe.Pointer) {
+func (d ServerDecoration) OnDestroy(cb func(ServerDecoration)) Listener {
+	return man.add(d.p, &d.p.events.destroy, func(unsafe.Pointer) {
 		cb(d)
 	})
 }
 
-func (d ServerDecoration) OnMode(cb func(ServerDecoration)) {
-	man.add(unsafe.Pointer(d.p), &d.p.events.mode, func(unsafe.Pointer) {
+func (d ServerDecoration) OnMode(cb func(ServerDecoration)) Listener {
+	return man.add(d.p, &d.p.events.mode, func(unsafe.Pointer) {
 		cb(d)
 	})
 }
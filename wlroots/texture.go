@@ -0,0 +1,139 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"image"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server libdrm
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdlib.h>
+// #include <drm_fourcc.h>
+// #include <pixman.h>
+// #include <wlr/render/wlr_texture.h>
+//
+// static inline bool _wlr_texture_update_from_buffer(struct wlr_texture *texture,
+//		struct wlr_buffer *buffer, int x, int y, int width, int height) {
+//		pixman_region32_t damage;
+//		pixman_region32_init_rect(&damage, x, y, width, height);
+//		bool ok = wlr_texture_update_from_buffer(texture, buffer, &damage);
+//		pixman_region32_fini(&damage);
+//		return ok;
+// }
+//
+// static inline bool _wlr_texture_read_pixels(struct wlr_texture *texture, void *data,
+//		uint32_t format, uint32_t stride) {
+//		struct wlr_texture_read_pixels_options options = {
+//			.data = data,
+//			.format = format,
+//			.stride = stride,
+//			.src_box = { .width = texture->width, .height = texture->height },
+//		};
+//		return wlr_texture_read_pixels(texture, &options);
+// }
+import "C"
+
+type Texture struct {
+	p *C.struct_wlr_texture
+}
+
+func (t Texture) Destroy() {
+	C.wlr_texture_destroy(t.p)
+}
+
+func (t Texture) Nil() bool {
+	return t.p == nil
+}
+
+func (t Texture) Width() int {
+	return int(t.p.width)
+}
+
+func (t Texture) Height() int {
+	return int(t.p.height)
+}
+
+/**
+ * Create a new texture from raw pixel data. `stride` is in bytes. The returned
+ * texture is mutable.
+ */
+func (r Renderer) TextureFromPixels(format uint32, stride int, width int, height int, data []byte) (Texture, error) {
+	debugCheck(unsafe.Pointer(r.p))
+	bpp, found := bytesPerPixel[format]
+	if !found {
+		return Texture{}, errors.New("unsupported pixel format " + FormatName(format))
+	}
+	if width <= 0 || height <= 0 || stride < width*bpp {
+		return Texture{}, errors.New("invalid texture size or stride")
+	}
+	if len(data) < stride*(height-1)+width*bpp {
+		return Texture{}, errors.New("pixel data too short for texture size")
+	}
+	p := C.wlr_texture_from_pixels(r.p, C.uint32_t(format), C.uint32_t(stride),
+		C.uint32_t(width), C.uint32_t(height), unsafe.Pointer(&data[0]))
+	if p == nil {
+		return Texture{}, errors.New("failed to create texture from pixels")
+	}
+	return Texture{p: p}, nil
+}
+
+/**
+ * Create a new texture from a buffer.
+ */
+func (r Renderer) TextureFromBuffer(b Buffer) (Texture, error) {
+	debugCheck(unsafe.Pointer(r.p))
+	p := C.wlr_texture_from_buffer(r.p, b.p)
+	if p == nil {
+		return Texture{}, errors.New("failed to create texture from buffer")
+	}
+	return Texture{p: p}, nil
+}
+
+/**
+ * Update a texture with the contents of a buffer.
+ *
+ * The update might be rejected (in case the texture is immutable, the buffer
+ * has an unsupported type/format, etc), so callers must be prepared to fall
+ * back to re-creating the texture from scratch via TextureFromBuffer.
+ *
+ * The damage is given in buffer coordinates.
+ */
+func (t Texture) Update(b Buffer, damage image.Rectangle) error {
+	debugCheck(unsafe.Pointer(t.p))
+	if !C._wlr_texture_update_from_buffer(t.p, b.p, C.int(damage.Min.X), C.int(damage.Min.Y),
+		C.int(damage.Dx()), C.int(damage.Dy())) {
+		return errors.New("texture can't be updated from buffer")
+	}
+	return nil
+}
+
+// ReadPixels copies the whole texture back from the renderer. It is slow, as
+// it has to wait for the GPU, and is mostly useful to check rendering results
+// in tests.
+func (t Texture) ReadPixels() (*image.RGBA, error) {
+	debugCheck(unsafe.Pointer(t.p))
+	width, height := t.Width(), t.Height()
+	stride := width * 4
+
+	// image.RGBA has the byte order of DRM_FORMAT_ABGR8888; the renderer
+	// writes into C memory, as it mustn't be handed Go pointers in a struct
+	data := C.malloc(C.size_t(stride * height))
+	if data == nil {
+		return nil, errors.New("failed to allocate pixel memory")
+	}
+	defer C.free(data)
+
+	if !C._wlr_texture_read_pixels(t.p, data, C.DRM_FORMAT_ABGR8888, C.uint32_t(stride)) {
+		return nil, errors.New("failed to read texture pixels")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	copy(img.Pix, unsafe.Slice((*byte)(data), stride*height))
+	return img, nil
+}
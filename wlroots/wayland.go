@@ -0,0 +1,101 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server wayland-client
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdlib.h>
+// #include <wayland-client-core.h>
+// #include <wlr/backend/wayland.h>
+import "C"
+
+/**
+ * A backend that runs nested in another Wayland compositor. Every output is a
+ * toplevel window on the parent compositor.
+ */
+type WaylandBackend struct {
+	Backend
+}
+
+// RemoteDisplay is a client connection to a parent Wayland compositor, for use
+// with NewWaylandBackend.
+type RemoteDisplay struct {
+	p *C.struct_wl_display
+}
+
+// ConnectRemoteDisplay connects to the compositor listening on the socket
+// name. If name is empty, the WAYLAND_DISPLAY environment variable is used.
+func ConnectRemoteDisplay(name string) (RemoteDisplay, error) {
+	var cname *C.char
+	if name != "" {
+		cname = C.CString(name)
+		defer C.free(unsafe.Pointer(cname))
+	}
+	p := C.wl_display_connect(cname)
+	if p == nil {
+		return RemoteDisplay{}, errors.New("failed to connect to remote display")
+	}
+	return RemoteDisplay{p: p}, nil
+}
+
+func (d RemoteDisplay) Nil() bool {
+	return d.p == nil
+}
+
+// Disconnect closes the connection. A backend using it must have been
+// destroyed first.
+func (d RemoteDisplay) Disconnect() {
+	C.wl_display_disconnect(d.p)
+}
+
+/**
+ * Creates a new Wayland backend. This backend will be created with no outputs;
+ * you must use OutputCreate to add them.
+ *
+ * If remoteDisplay is Nil, the backend connects to the compositor named by
+ * WAYLAND_DISPLAY and closes the connection on destroy. Otherwise the
+ * connection stays owned by the caller, who may only disconnect it once the
+ * backend has been destroyed, as wlroots keeps using it during destruction.
+ */
+func NewWaylandBackend(loop EventLoop, remoteDisplay RemoteDisplay) (WaylandBackend, error) {
+	p := C.wlr_wl_backend_create(loop.p, remoteDisplay.p)
+	if p == nil {
+		return WaylandBackend{}, errors.New("failed to create wayland backend")
+	}
+	man.track(p, &p.events.destroy)
+	return WaylandBackend{Backend{p: p}}, nil
+}
+
+/**
+ * Adds a new output to this backend.
+ *
+ * This creates a new toplevel on the parent compositor. The output is
+ * announced through the new output event, and is destroyed along with the
+ * window when the user closes it.
+ */
+func (b WaylandBackend) OutputCreate() (Output, error) {
+	debugCheck(unsafe.Pointer(b.p))
+	p := C.wlr_wl_output_create(b.p)
+	if p == nil {
+		return Output{}, errors.New("failed to create wayland output")
+	}
+	return wrapOutput(unsafe.Pointer(p)), nil
+}
+
+func (b Backend) IsWayland() bool {
+	debugCheck(unsafe.Pointer(b.p))
+	return bool(C.wlr_backend_is_wl(b.p))
+}
+
+func (o Output) IsWayland() bool {
+	debugCheck(unsafe.Pointer(o.p))
+	return bool(C.wlr_output_is_wl(o.p))
+}
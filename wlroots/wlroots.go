@@ -1,7 +1,11 @@
 package wlroots
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"unsafe"
 )
 
@@ -17,7 +21,6 @@ import (
 // #include <wlr/types/wlr_output_layout.h>
 // #include <wlr/types/wlr_xcursor_manager.h>
 // #include <wlr/types/wlr_xdg_shell.h>
-// #include <wlr/render/wlr_texture.h>
 // #include <wlr/types/wlr_linux_dmabuf_v1.h>
 // #include <wlr/types/wlr_matrix.h>
 // #include <wlr/util/box.h>
@@ -51,50 +54,6 @@ const (
 	EdgeRight  Edges = C.WLR_EDGE_RIGHT
 )
 
-type Texture struct {
-	p *C.struct_wlr_texture
-}
-
-func (t Texture) Destroy() {
-	C.wlr_texture_destroy(t.p)
-}
-
-func (t Texture) Nil() bool {
-	return t.p == nil
-}
-
-type OutputLayout struct {
-	p *C.struct_wlr_output_layout
-}
-type OutputLayoutOutput struct {
-	p *C.struct_wlr_output_layout_output
-}
-
-func NewOutputLayout(d Display) OutputLayout {
-	return OutputLayoutCreate(d)
-}
-
-func OutputLayoutCreate(d Display) OutputLayout {
-	p := C.wlr_output_layout_create(d.p)
-	man.track(unsafe.Pointer(p), &p.events.destroy)
-	return OutputLayout{p: p}
-}
-
-func (l OutputLayout) Destroy() {
-	C.wlr_output_layout_destroy(l.p)
-}
-
-func (l OutputLayout) AddOutputAuto(output Output) OutputLayoutOutput {
-	p := C.wlr_output_layout_add_auto(l.p, output.p)
-	return OutputLayoutOutput{p: p}
-}
-
-func (l OutputLayout) Coords(output Output) (x float64, y float64) {
-	var ox, oy C.double
-	C.wlr_output_layout_output_coords(l.p, output.p, &ox, &oy)
-	return float64(ox), float64(oy)
-}
-
 type Matrix [9]float32
 
 func (m *Matrix) ProjectBox(box *GeoBox, transform uint32, rotation float32, projection *Matrix) {
@@ -125,20 +84,26 @@ type DMABuf struct {
 
 func NewDMABuf(display Display, renderer Renderer) DMABuf {
 	p := C.wlr_linux_dmabuf_v1_create_with_renderer(display.p, 4, renderer.p)
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return DMABuf{p: p}
 }
 
-func (b DMABuf) OnDestroy(cb func(DMABuf)) {
-	man.add(unsafe.Pointer(b.p), &b.p.events.destroy, func(unsafe.Pointer) {
+func (b DMABuf) OnDestroy(cb func(DMABuf)) Listener {
+	return man.add(b.p, &b.p.events.destroy, func(unsafe.Pointer) {
 		cb(b)
 	})
 }
 
+var ErrDisplayTerminated = errors.New("display terminated")
+
 type Display struct {
 	p *C.struct_wl_display
 }
 
+// terminated records the displays Terminate has been called on, so that
+// RunContext can tell why the event loop woke up.
+var terminated sync.Map
+
 func DisplayCreate() {
 	NewDisplay()
 }
@@ -148,7 +113,15 @@ func NewDisplay() Display {
 	d := Display{p: p}
 	d.OnDestroy(func(Display) {
 		man.delete(unsafe.Pointer(p))
+		terminated.Delete(p)
+		globalFilters.Delete(p)
 	})
+
+	// allow other goroutines to schedule work on the event loop
+	evl := EventLoop{p: C.wl_display_get_event_loop(p)}
+	if err := initEventLoop(evl); err != nil {
+		slog.Warn("EventLoop.Post is unavailable", "err", err)
+	}
 	return d
 }
 
@@ -161,7 +134,7 @@ func (d Display) BackendAutocreate() (Backend, error) {
 	if p == nil {
 		return Backend{}, errors.New("failed to create wlr_backend")
 	}
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return Backend{p: p}, nil
 }
 
@@ -171,7 +144,7 @@ func (d Display) NewSubCompositor() SubCompositor {
 
 func (d Display) SubCompositorCreate() SubCompositor {
 	p := C.wlr_subcompositor_create(d.p)
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return SubCompositor{p: p}
 }
 
@@ -181,7 +154,11 @@ func (d Display) NewCompositor(version int, renderer Renderer) Compositor {
 
 func (d Display) CompositorCreate(version int, renderer Renderer) Compositor {
 	p := C.wlr_compositor_create(d.p, C.uint(version), renderer.p)
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
+	man.add(p, &p.events.new_surface, func(data unsafe.Pointer) {
+		surface := (*C.struct_wlr_surface)(data)
+		man.track(surface, &surface.events.destroy)
+	})
 	return Compositor{p: p}
 }
 
@@ -191,7 +168,7 @@ func (d Display) NewDataDeviceManager() DataDeviceManager {
 
 func (d Display) DataDeviceManagerCreate() DataDeviceManager {
 	p := C.wlr_data_device_manager_create(d.p)
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return DataDeviceManager{p: p}
 }
 
@@ -203,7 +180,7 @@ func (d Display) SeatCreate(name string) Seat {
 	s := C.CString(name)
 	p := C.wlr_seat_create(d.p, s)
 	C.free(unsafe.Pointer(s))
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return Seat{p: p}
 }
 
@@ -213,7 +190,7 @@ func (d Display) NewXDGShell(version int) XDGShell {
 
 func (d Display) XDGShellCreate(version int) XDGShell {
 	p := C.wlr_xdg_shell_create(d.p, C.uint(version))
-	man.track(unsafe.Pointer(p), &p.events.destroy)
+	man.track(p, &p.events.destroy)
 	return XDGShell{p: p}
 }
 
@@ -221,28 +198,77 @@ func (d Display) Destroy() {
 	C.wl_display_destroy(d.p)
 }
 
-func (d Display) OnDestroy(cb func(Display)) {
-	l := man.add(unsafe.Pointer(d.p), nil, func(data unsafe.Pointer) {
+func (d Display) OnDestroy(cb func(Display)) Listener {
+	l := man.add(d.p, nil, func(data unsafe.Pointer) {
 		cb(d)
 	})
-	C.wl_display_add_destroy_listener(d.p, l.p)
+	C.wl_display_add_destroy_listener(d.p, l.l.p)
+	return l
 }
 
+// Run runs the event loop until Terminate is called. Like EventLoop.Dispatch,
+// it must be called from a goroutine locked to its OS thread.
 func (d Display) Run() {
+	setDispatcher()
 	C.wl_display_run(d.p)
 }
 
+// RunContext runs the event loop until Terminate is called, ctx is done or
+// dispatching fails. The returned error describes why it stopped: it is
+// ErrDisplayTerminated after a call to Terminate and the cause of ctx after
+// cancellation.
+//
+// The calling goroutine should be locked to its OS thread, see
+// EventLoop.Dispatch.
+func (d Display) RunContext(ctx context.Context) error {
+	evl := EventLoop{p: C.wl_display_get_event_loop(d.p)}
+	terminated.Delete(d.p)
+
+	// wake up the event loop so it notices the cancellation
+	stop := context.AfterFunc(ctx, func() {
+		evl.Post(func() {})
+	})
+	defer stop()
+
+	for {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if _, found := terminated.LoadAndDelete(d.p); found {
+			return ErrDisplayTerminated
+		}
+
+		d.FlushClients()
+		if err := evl.Dispatch(-1); err != nil {
+			return fmt.Errorf("can't dispatch event loop: %w", err)
+		}
+	}
+}
+
 func (d Display) Terminate() {
+	terminated.Store(d.p, struct{}{})
 	C.wl_display_terminate(d.p)
 }
 
+// Destroyer is implemented by every object that can be destroyed explicitly.
+type Destroyer interface {
+	Destroy()
+}
+
+// Shutdown tears down the compositor after the event loop has stopped. It
+// disconnects all clients, destroys objs in the given order and finally
+// destroys the display itself. A typical order is the scene, the cursor
+// manager and then the output layout.
+func (d Display) Shutdown(objs ...Destroyer) {
+	d.DestroyClients()
+	for _, obj := range objs {
+		obj.Destroy()
+	}
+	d.Destroy()
+}
+
 func (d Display) EventLoop() EventLoop {
-	p := C.wl_display_get_event_loop(d.p)
-	evl := EventLoop{p: p}
-	evl.OnDestroy(func(EventLoop) {
-		man.delete(unsafe.Pointer(p))
-	})
-	return evl
+	return EventLoop{p: C.wl_display_get_event_loop(d.p)}
 }
 
 func (d Display) AddSocketAuto() (string, error) {
@@ -254,6 +280,46 @@ func (d Display) AddSocketAuto() (string, error) {
 	return C.GoString(socket), nil
 }
 
+/**
+ * Add a Unix socket to the display with the given name. The socket is created
+ * in $XDG_RUNTIME_DIR.
+ */
+func (d Display) AddSocket(name string) error {
+	s := C.CString(name)
+	defer C.free(unsafe.Pointer(s))
+	if C.wl_display_add_socket(d.p, s) != 0 {
+		return fmt.Errorf("can't add wayland socket %s", name)
+	}
+
+	return nil
+}
+
+/**
+ * Add an already bound and listening socket to the display, e.g. one passed
+ * in by systemd socket activation. The display takes ownership of fd.
+ */
+func (d Display) AddSocketFD(fd int) error {
+	if C.wl_display_add_socket_fd(d.p, C.int(fd)) != 0 {
+		return errors.New("can't add wayland socket fd")
+	}
+
+	return nil
+}
+
+/**
+ * Create a client for an already connected socket, e.g. one end of a
+ * socketpair whose other end is handed to a child process through
+ * WAYLAND_SOCKET. The client takes ownership of fd.
+ */
+func (d Display) CreateClient(fd int) (Client, error) {
+	p := C.wl_client_create(d.p, C.int(fd))
+	if p == nil {
+		return Client{}, errors.New("can't create wayland client")
+	}
+
+	return Client{p: p}, nil
+}
+
 func (d Display) FlushClients() {
 	C.wl_display_flush_clients(d.p)
 }
@@ -266,8 +332,8 @@ type DataDeviceManager struct {
 	p *C.struct_wlr_data_device_manager
 }
 
-func (m DataDeviceManager) OnDestroy(cb func(DataDeviceManager)) {
-	man.add(unsafe.Pointer(m.p), &m.p.events.destroy, func(unsafe.Pointer) {
+func (m DataDeviceManager) OnDestroy(cb func(DataDeviceManager)) Listener {
+	return man.add(m.p, &m.p.events.destroy, func(unsafe.Pointer) {
 		cb(m)
 	})
 }
@@ -0,0 +1,72 @@
+package wlroots
+
+/*
+ * This an unstable interface of wlroots. No guarantees are made regarding the
+ * future consistency of this API.
+ */
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// #cgo pkg-config: wlroots-0.18 wayland-server
+// #cgo CFLAGS: -D_GNU_SOURCE -DWLR_USE_UNSTABLE
+// #include <stdlib.h>
+// #include <wlr/backend/x11.h>
+import "C"
+
+/**
+ * A backend that runs as a client of an X11 server. Every output is an X11
+ * window.
+ */
+type X11Backend struct {
+	Backend
+}
+
+/**
+ * Creates a new X11 backend. This backend will be created with no outputs;
+ * you must use OutputCreate to add them.
+ *
+ * The xDisplay argument is the name of the X display socket. If it is empty,
+ * the DISPLAY environment variable is used.
+ */
+func NewX11Backend(loop EventLoop, xDisplay string) (X11Backend, error) {
+	var name *C.char
+	if xDisplay != "" {
+		name = C.CString(xDisplay)
+		defer C.free(unsafe.Pointer(name))
+	}
+
+	p := C.wlr_x11_backend_create(loop.p, name)
+	if p == nil {
+		return X11Backend{}, errors.New("failed to create x11 backend")
+	}
+	man.track(p, &p.events.destroy)
+	return X11Backend{Backend{p: p}}, nil
+}
+
+/**
+ * Adds a new output to this backend.
+ *
+ * This creates a new X11 window, which is destroyed together with the output
+ * when the window is closed.
+ */
+func (b X11Backend) OutputCreate() (Output, error) {
+	debugCheck(unsafe.Pointer(b.p))
+	p := C.wlr_x11_output_create(b.p)
+	if p == nil {
+		return Output{}, errors.New("failed to create x11 output")
+	}
+	return wrapOutput(unsafe.Pointer(p)), nil
+}
+
+func (b Backend) IsX11() bool {
+	debugCheck(unsafe.Pointer(b.p))
+	return bool(C.wlr_backend_is_x11(b.p))
+}
+
+func (o Output) IsX11() bool {
+	debugCheck(unsafe.Pointer(o.p))
+	return bool(C.wlr_output_is_x11(o.p))
+}
@@ -64,34 +64,38 @@ func (s XDGShell) PingTimeout() int {
 	return int(s.p.ping_timeout)
 }
 
-func (s XDGShell) OnDestroy(cb func(XDGShell)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.destroy, func(unsafe.Pointer) {
+func (s XDGShell) OnDestroy(cb func(XDGShell)) Listener {
+	return man.add(s.p, &s.p.events.destroy, func(unsafe.Pointer) {
 		cb(s)
 	})
 }
 
-func (s XDGShell) OnNewSurface(cb func(XDGSurface)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.new_surface, func(data unsafe.Pointer) {
+func (s XDGShell) OnNewSurface(cb func(XDGSurface)) Listener {
+	return man.add(s.p, &s.p.events.new_surface, func(data unsafe.Pointer) {
 		surface := XDGSurface{p: (*C.struct_wlr_xdg_surface)(data)}
-		man.add(unsafe.Pointer(surface.p), &surface.p.events.destroy, func(data unsafe.Pointer) {
+		man.addLast(surface.p, &surface.p.events.destroy, func(data unsafe.Pointer) {
+			// read the role object before surface.p is marked as destroyed
+			toplevel := *(*unsafe.Pointer)(unsafe.Pointer(&surface.p.anon0[0]))
 			man.delete(unsafe.Pointer(surface.p))
-			man.delete(unsafe.Pointer(surface.TopLevel().p))
+			man.delete(toplevel)
 		})
-		man.add(unsafe.Pointer(surface.p.surface), &surface.p.surface.events.destroy, func(data unsafe.Pointer) {
+		man.addLast(surface.p.surface, &surface.p.surface.events.destroy, func(data unsafe.Pointer) {
 			man.delete(unsafe.Pointer(surface.p.surface))
 		})
 		cb(surface)
 	})
 }
 
-func (s XDGShell) OnNewTopLevel(cb func(XDGTopLevel)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.new_toplevel, func(data unsafe.Pointer) {
+func (s XDGShell) OnNewTopLevel(cb func(XDGTopLevel)) Listener {
+	return man.add(s.p, &s.p.events.new_toplevel, func(data unsafe.Pointer) {
+		debugAlive(data)
 		cb(XDGTopLevel{p: (*C.struct_wlr_xdg_toplevel)(data)})
 	})
 }
 
-func (s XDGShell) OnNewPopup(cb func(XDGPopup)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.new_popup, func(data unsafe.Pointer) {
+func (s XDGShell) OnNewPopup(cb func(XDGPopup)) Listener {
+	return man.add(s.p, &s.p.events.new_popup, func(data unsafe.Pointer) {
+		debugAlive(data)
 		cb(XDGPopup{p: (*C.struct_wlr_xdg_popup)(data)})
 	})
 }
@@ -115,6 +119,7 @@ func (x XDGSurface) Nil() bool {
 }
 
 func (x XDGSurface) Walk(visit XDGSurfaceWalkFunc) {
+	debugCheck(unsafe.Pointer(x.p))
 	xdgSurfaceWalkersMutex.Lock()
 	xdgSurfaceWalkers[x.p] = visit
 	xdgSurfaceWalkersMutex.Unlock()
@@ -131,102 +136,140 @@ func (x XDGSurface) Walk(visit XDGSurfaceWalkFunc) {
  * if the role was never set.
  */
 func (x XDGSurface) Role() XDGSurfaceRole {
+	debugCheck(unsafe.Pointer(x.p))
 	return XDGSurfaceRole(x.p.role)
 }
 
 func (x XDGSurface) Popup() XDGPopup {
+	debugCheck(unsafe.Pointer(x.p))
 	p := *(*unsafe.Pointer)(unsafe.Pointer(&x.p.anon0[0]))
 	return XDGPopup{p: (*C.struct_wlr_xdg_popup)(p)}
 }
 func (x XDGSurface) TopLevel() XDGTopLevel {
+	debugCheck(unsafe.Pointer(x.p))
 	p := *(*unsafe.Pointer)(unsafe.Pointer(&x.p.anon0[0]))
 	return XDGTopLevel{p: (*C.struct_wlr_xdg_toplevel)(p)}
 }
 
 func (x XDGSurface) TopLevelSetActivated(activated bool) {
+	debugCheck(unsafe.Pointer(x.p))
 	C.wlr_xdg_toplevel_set_activated(x.TopLevel().p, C.bool(activated))
 }
 
 func (x XDGSurface) TopLevelSetSize(width uint32, height uint32) {
+	debugCheck(unsafe.Pointer(x.p))
 	C.wlr_xdg_toplevel_set_size(x.TopLevel().p, C.int(width), C.int(height))
 }
 
 func (x XDGSurface) TopLevelSetTiled(edges Edges) {
+	debugCheck(unsafe.Pointer(x.p))
 	C.wlr_xdg_toplevel_set_tiled(x.TopLevel().p, C.uint(edges))
 }
 
 func (x XDGSurface) SendClose() {
+	debugCheck(unsafe.Pointer(x.p))
 	C.wlr_xdg_toplevel_send_close(x.TopLevel().p)
 }
 
 func (x XDGSurface) SceneTree() SceneTree {
+	debugCheck(unsafe.Pointer(x.p))
 	slog.Debug("XDGSurface SceneTree()", "x.p", x.p)
 	slog.Debug("XDGSurface SceneTree()", "x.p.data", x.p.data)
 	return SceneTree{p: (*C.struct_wlr_scene_tree)(x.p.data)}
 }
 
 func (x XDGSurface) Ping() {
+	debugCheck(unsafe.Pointer(x.p))
 	C.wlr_xdg_surface_ping(x.p)
 }
 
 func (x XDGSurface) Surface() Surface {
+	debugCheck(unsafe.Pointer(x.p))
 	return Surface{p: x.p.surface}
 }
 
+func (x XDGSurface) Client() Client {
+	debugCheck(unsafe.Pointer(x.p))
+	return Client{p: x.p.client.client}
+}
+
 func (x XDGSurface) SurfaceAt(sx float64, sy float64) (surface Surface, subX float64, subY float64) {
+	debugCheck(unsafe.Pointer(x.p))
 	var csubX, csubY C.double
 	p := C.wlr_xdg_surface_surface_at(x.p, C.double(sx), C.double(sy), &csubX, &csubY)
 	return Surface{p: p}, float64(csubX), float64(csubY)
 }
 
 func (x XDGSurface) SetData(tree SceneTree) {
+	debugCheck(unsafe.Pointer(x.p))
 	x.p.data = unsafe.Pointer(tree.p)
 	slog.Debug("XDGSurface SetData", "x.p", x.p)
 	slog.Debug("XDGSurface SetData", "x.data:", x.p.data)
 }
 
 func (x XDGSurface) ScheduleConfigure() {
+	debugCheck(unsafe.Pointer(x.p))
 	C.wlr_xdg_surface_schedule_configure(x.p)
 }
 
-func (x XDGSurface) OnMap(cb func(XDGSurface)) {
-	man.add(unsafe.Pointer(x.p), &x.p.surface.events._map, func(data unsafe.Pointer) {
+func (x XDGSurface) OnMap(cb func(XDGSurface)) Listener {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.add(x.p, &x.p.surface.events._map, func(data unsafe.Pointer) {
 		cb(x)
 	})
 }
 
-func (x XDGSurface) OnUnmap(cb func(XDGSurface)) {
-	man.add(unsafe.Pointer(x.p), &x.p.surface.events.unmap, func(data unsafe.Pointer) {
+func (x XDGSurface) OnUnmap(cb func(XDGSurface)) Listener {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.add(x.p, &x.p.surface.events.unmap, func(data unsafe.Pointer) {
 		cb(x)
 	})
 }
 
-func (x XDGSurface) OnCommit(cb func(XDGSurface)) {
-	man.add(unsafe.Pointer(x.p), &x.p.surface.events.commit, func(data unsafe.Pointer) {
+func (x XDGSurface) OnCommit(cb func(XDGSurface)) Listener {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.add(x.p, &x.p.surface.events.commit, func(data unsafe.Pointer) {
 		cb(x)
 	})
 }
 
-func (x XDGSurface) OnDestroy(cb func(XDGSurface)) {
-	man.add(unsafe.Pointer(x.p), &x.p.events.destroy, func(data unsafe.Pointer) {
+func (x XDGSurface) OnDestroy(cb func(XDGSurface)) Listener {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.add(x.p, &x.p.events.destroy, func(data unsafe.Pointer) {
 		cb(x)
 	})
 }
 
-func (x XDGSurface) OnPingTimeout(cb func(XDGSurface)) {
-	man.add(unsafe.Pointer(x.p), &x.p.events.ping_timeout, func(data unsafe.Pointer) {
+// SetUserData attaches an arbitrary Go value to the xdg_surface, e.g. the
+// compositor's own view struct. Unlike SetData it accepts any type.
+func (x XDGSurface) SetUserData(v any) {
+	debugCheck(unsafe.Pointer(x.p))
+	man.setUserData(x.p, &x.p.events.destroy, v)
+}
+
+// UserData returns the value set with SetUserData, or nil.
+func (x XDGSurface) UserData() any {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.getUserData(unsafe.Pointer(x.p))
+}
+
+func (x XDGSurface) OnPingTimeout(cb func(XDGSurface)) Listener {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.add(x.p, &x.p.events.ping_timeout, func(data unsafe.Pointer) {
 		cb(x)
 	})
 }
 
-func (x XDGSurface) OnNewPopup(cb func(XDGSurface, XDGPopup)) {
-	man.add(unsafe.Pointer(x.p), &x.p.events.ping_timeout, func(data unsafe.Pointer) {
+func (x XDGSurface) OnNewPopup(cb func(XDGSurface, XDGPopup)) Listener {
+	debugCheck(unsafe.Pointer(x.p))
+	return man.add(x.p, &x.p.events.ping_timeout, func(data unsafe.Pointer) {
 		popup := XDGPopup{p: (*C.struct_wlr_xdg_popup)(data)}
 		cb(x, popup)
 	})
 }
 
 func (x XDGSurface) Geometry() GeoBox {
+	debugCheck(unsafe.Pointer(x.p))
 	var cb C.struct_wlr_box
 	C.wlr_xdg_surface_get_geometry(x.p, &cb)
 
@@ -236,6 +279,7 @@ func (x XDGSurface) Geometry() GeoBox {
 }
 
 func (x XDGSurface) InitialCommit() bool {
+	debugCheck(unsafe.Pointer(x.p))
 	return bool(x.p.initial_commit)
 }
 
@@ -243,16 +287,18 @@ type XDGTopLevel struct {
 	p *C.struct_wlr_xdg_toplevel
 }
 
-func (t XDGTopLevel) OnRequestMove(cb func(client SeatClient, serial uint32)) {
-	man.add(unsafe.Pointer(t.p), &t.p.events.request_move, func(data unsafe.Pointer) {
+func (t XDGTopLevel) OnRequestMove(cb func(client SeatClient, serial uint32)) Listener {
+	debugCheck(unsafe.Pointer(t.p))
+	return man.add(t.p, &t.p.events.request_move, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_xdg_toplevel_move_event)(data)
 		client := SeatClient{p: event.seat}
 		cb(client, uint32(event.serial))
 	})
 }
 
-func (t XDGTopLevel) OnRequestResize(cb func(client SeatClient, serial uint32, edges Edges)) {
-	man.add(unsafe.Pointer(t.p), &t.p.events.request_resize, func(data unsafe.Pointer) {
+func (t XDGTopLevel) OnRequestResize(cb func(client SeatClient, serial uint32, edges Edges)) Listener {
+	debugCheck(unsafe.Pointer(t.p))
+	return man.add(t.p, &t.p.events.request_resize, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_xdg_toplevel_resize_event)(data)
 		client := SeatClient{p: event.seat}
 		cb(client, uint32(event.serial), Edges(event.edges))
@@ -263,22 +309,40 @@ func (t XDGTopLevel) Nil() bool {
 	return t.p == nil
 }
 
+// SetUserData attaches an arbitrary Go value to the toplevel until it is
+// destroyed.
+func (t XDGTopLevel) SetUserData(v any) {
+	debugCheck(unsafe.Pointer(t.p))
+	man.setUserData(t.p, &t.p.events.destroy, v)
+}
+
+// UserData returns the value set with SetUserData, or nil.
+func (t XDGTopLevel) UserData() any {
+	debugCheck(unsafe.Pointer(t.p))
+	return man.getUserData(unsafe.Pointer(t.p))
+}
+
 func (t XDGTopLevel) Title() string {
+	debugCheck(unsafe.Pointer(t.p))
 	return C.GoString(t.p.title)
 }
 
 func (t XDGTopLevel) AppId() string {
+	debugCheck(unsafe.Pointer(t.p))
 	return C.GoString(t.p.app_id)
 }
 
 func (t XDGTopLevel) Parent() XDGTopLevel {
+	debugCheck(unsafe.Pointer(t.p))
 	return XDGTopLevel{p: t.p.parent}
 }
 
 func (t XDGTopLevel) Base() XDGSurface {
+	debugCheck(unsafe.Pointer(t.p))
 	return XDGSurface{p: t.p.base}
 }
 
 func (t XDGTopLevel) SetActivated(activated bool) {
+	debugCheck(unsafe.Pointer(t.p))
 	C.wlr_xdg_toplevel_set_activated(t.p, C.bool(activated))
 }
@@ -35,11 +35,11 @@ func (x XWayland) Destroy() {
 	C.wlr_xwayland_destroy(x.p)
 }
 
-func (x XWayland) OnNewSurface(cb func(XWaylandSurface)) {
-	man.add(unsafe.Pointer(x.p), &x.p.events.new_surface, func(data unsafe.Pointer) {
+func (x XWayland) OnNewSurface(cb func(XWaylandSurface)) Listener {
+	return man.add(x.p, &x.p.events.new_surface, func(data unsafe.Pointer) {
 		surface := XWaylandSurface{p: (*C.struct_wlr_xwayland_surface)(data)}
-		man.track(unsafe.Pointer(surface.p), &surface.p.events.destroy)
-		man.add(unsafe.Pointer(surface.p.surface), &surface.p.surface.events.destroy, func(data unsafe.Pointer) {
+		man.track(surface.p, &surface.p.events.destroy)
+		man.addLast(surface.p.surface, &surface.p.surface.events.destroy, func(data unsafe.Pointer) {
 			man.delete(unsafe.Pointer(surface.p.surface))
 		})
 		cb(surface)
@@ -71,39 +71,39 @@ func (s XWaylandSurface) Configure(x int16, y int16, width uint16, height uint16
 	C.wlr_xwayland_surface_configure(s.p, C.int16_t(x), C.int16_t(y), C.uint16_t(width), C.uint16_t(height))
 }
 
-func (s XWaylandSurface) OnMap(cb func(XWaylandSurface)) {
-	man.add(unsafe.Pointer(s.p), &s.p.surface.events._map, func(data unsafe.Pointer) {
+func (s XWaylandSurface) OnMap(cb func(XWaylandSurface)) Listener {
+	return man.add(s.p, &s.p.surface.events._map, func(data unsafe.Pointer) {
 		cb(s)
 	})
 }
 
-func (s XWaylandSurface) OnUnmap(cb func(XWaylandSurface)) {
-	man.add(unsafe.Pointer(s.p), &s.p.surface.events.unmap, func(data unsafe.Pointer) {
+func (s XWaylandSurface) OnUnmap(cb func(XWaylandSurface)) Listener {
+	return man.add(s.p, &s.p.surface.events.unmap, func(data unsafe.Pointer) {
 		cb(s)
 	})
 }
 
-func (s XWaylandSurface) OnDestroy(cb func(XWaylandSurface)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.destroy, func(data unsafe.Pointer) {
+func (s XWaylandSurface) OnDestroy(cb func(XWaylandSurface)) Listener {
+	return man.add(s.p, &s.p.events.destroy, func(data unsafe.Pointer) {
 		cb(s)
 	})
 }
 
-func (s XWaylandSurface) OnRequestMove(cb func(surface XWaylandSurface)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.request_move, func(data unsafe.Pointer) {
+func (s XWaylandSurface) OnRequestMove(cb func(surface XWaylandSurface)) Listener {
+	return man.add(s.p, &s.p.events.request_move, func(data unsafe.Pointer) {
 		cb(s)
 	})
 }
 
-func (s XWaylandSurface) OnRequestResize(cb func(surface XWaylandSurface, edges Edges)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.request_resize, func(data unsafe.Pointer) {
+func (s XWaylandSurface) OnRequestResize(cb func(surface XWaylandSurface, edges Edges)) Listener {
+	return man.add(s.p, &s.p.events.request_resize, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_xwayland_resize_event)(data)
 		cb(s, Edges(event.edges))
 	})
 }
 
-func (s XWaylandSurface) OnRequestConfigure(cb func(surface XWaylandSurface, x int16, y int16, width uint16, height uint16)) {
-	man.add(unsafe.Pointer(s.p), &s.p.events.request_configure, func(data unsafe.Pointer) {
+func (s XWaylandSurface) OnRequestConfigure(cb func(surface XWaylandSurface, x int16, y int16, width uint16, height uint16)) Listener {
+	return man.add(s.p, &s.p.events.request_configure, func(data unsafe.Pointer) {
 		event := (*C.struct_wlr_xwayland_surface_configure_event)(data)
 		cb(s, int16(event.x), int16(event.y), uint16(event.width), uint16(event.height))
 	})